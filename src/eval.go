@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"lianwMS/appcat_validation/logger"
+	"lianwMS/appcat_validation/testcase"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	evalFilePrefix string = "appcat_eval"
+)
+
+// runEval implements the "eval" command: it runs the configured AI judge over a labeled dataset
+// and writes a Markdown report of precision, recall, confusion matrix and per-category accuracy,
+// with the per-incident results next to it.
+func runEval(args []string) {
+	wd, _ := os.Getwd()
+	flags := flag.NewFlagSet("eval", flag.ExitOnError)
+	datasetPath := flags.String("dataset", filepath.Join(wd, "..", "data", "eval"), "Labeled dataset YAML file, or folder of YAML files")
+	sourceRepoFolder := flags.String("source", filepath.Join(wd, "..", "data", "projects"), "Path to source repo folder, used with -ai-context-lines")
+	outputFolder := flags.String("output", filepath.Join(wd, "..", "testResults"), "Path to output folder")
+	judgeOptions := addJudgeFlags(flags)
+	logLevel, logFormat := addLogFlags(flags)
+	flags.Parse(args)
+
+	logOptions, err := parseLogOptions(*logLevel, *logFormat)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(ExitInfrastructure)
+	}
+
+	dataset, err := testcase.LoadEvalDataset(*datasetPath)
+	if err != nil {
+		fmt.Printf("Error loading dataset: %v\n", err)
+		os.Exit(ExitInfrastructure)
+	}
+	judge, prompt, err := judgeOptions.newJudge()
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(ExitInfrastructure)
+	}
+	if err := os.MkdirAll(*outputFolder, 0755); err != nil {
+		fmt.Printf("Failed to create output folder: %v\n", err)
+		os.Exit(ExitInfrastructure)
+	}
+
+	timeInFileName := time.Now().Format("20060102_150405")
+	logFilePath := filepath.Join(*outputFolder, fmt.Sprintf("%s_%s%s", evalFilePrefix, timeInFileName, LogExtension))
+	if err := logger.Init(logFilePath, false, logOptions); err != nil {
+		fmt.Printf("Failed to initialize logger: %v\n", err)
+		os.Exit(ExitInfrastructure)
+	}
+	defer logger.CloseLogFile()
+	logger.Get().Printf("Evaluating judge %s with prompt %s on %d incidents from: %s", judge.Name(), prompt.ID(), len(dataset), *datasetPath)
+
+	results := testcase.EvaluateJudge(dataset, judge, prompt, *sourceRepoFolder, *judgeOptions.contextLines, *judgeOptions.parallelism)
+	promptVersion := prompt.ID()
+	if len(results) > 0 {
+		promptVersion = results[0].Predicted.PromptVersion
+	}
+	report := testcase.BuildEvalReport(results, judge.Name(), promptVersion)
+
+	resultsData, err := yaml.Marshal(results)
+	if err != nil {
+		fmt.Printf("Failed to marshal evaluation results: %v\n", err)
+		os.Exit(ExitInfrastructure)
+	}
+	resultsFilePath := filepath.Join(*outputFolder, fmt.Sprintf("%s_%s%s", evalFilePrefix, timeInFileName, testcase.YamlExtension))
+	if err := os.WriteFile(resultsFilePath, resultsData, 0644); err != nil {
+		fmt.Printf("Failed to write evaluation results: %v\n", err)
+		os.Exit(ExitInfrastructure)
+	}
+	reportFilePath := filepath.Join(*outputFolder, fmt.Sprintf("%s_%s%s", evalFilePrefix, timeInFileName, TestResultExtension))
+	if err := os.WriteFile(reportFilePath, []byte(report), 0644); err != nil {
+		fmt.Printf("Failed to write evaluation report: %v\n", err)
+		os.Exit(ExitInfrastructure)
+	}
+	fmt.Print(report)
+	fmt.Printf("\nEvaluation report written to: %s\n", reportFilePath)
+}
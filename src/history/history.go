@@ -0,0 +1,86 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	HistoryFileName = "appcat_history.jsonl"
+)
+
+// ProjectRecord is the outcome of a single project in a run.
+type ProjectRecord struct {
+	Name            string         `json:"name"`
+	Status          string         `json:"status"`
+	RunStatus       string         `json:"runStatus,omitempty"`
+	Incidents       int            `json:"incidents"`
+	Rules           map[string]int `json:"rules,omitempty"`
+	Diffs           int            `json:"diffs"`
+	DurationSeconds float64        `json:"durationSeconds"`
+	// LogErrors is the number of error records in the AppCat analysis.log, -1 when there is none.
+	LogErrors int `json:"logErrors"`
+}
+
+// RunRecord is one line of the history file.
+type RunRecord struct {
+	RunId    string          `json:"runId"`
+	Time     time.Time       `json:"time"`
+	Projects []ProjectRecord `json:"projects"`
+}
+
+// GetHistoryFile returns the history file path in the given output folder.
+func GetHistoryFile(outputFolder string) string {
+	return filepath.Join(outputFolder, HistoryFileName)
+}
+
+// Append adds a run record as a new line to the history file, creating it if needed.
+func Append(historyFile string, record RunRecord) error {
+	file, err := os.OpenFile(historyFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file '%s': %w", historyFile, err)
+	}
+	defer file.Close()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run record: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write history file '%s': %w", historyFile, err)
+	}
+	return nil
+}
+
+// Load reads all run records from the history file, oldest first.
+func Load(historyFile string) ([]RunRecord, error) {
+	file, err := os.Open(historyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file '%s': %w", historyFile, err)
+	}
+	defer file.Close()
+
+	records := []RunRecord{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record RunRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed to parse history file '%s' line %d: %w", historyFile, lineNumber, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file '%s': %w", historyFile, err)
+	}
+	return records, nil
+}
@@ -0,0 +1,181 @@
+package history
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+)
+
+const (
+	JumpStatus        = "status"
+	JumpIncidents     = "total incidents"
+	JumpRuleIncidents = "rule incidents"
+)
+
+// Jump is a sudden change of a project between two consecutive runs.
+type Jump struct {
+	RunId   string
+	Project string
+	Rule    string
+	Reason  string
+	Before  string
+	After   string
+}
+
+// runStatusFailed is the run status of a project whose AppCat run left no artifact.
+const runStatusFailed = "FAILED"
+
+// hasCounts reports whether the incident counts of a project record can be compared: the output
+// was parsed and AppCat did not fail.
+func (p ProjectRecord) hasCounts() bool {
+	return p.Incidents >= 0 && p.RunStatus != runStatusFailed
+}
+
+// relativeChange returns the change from before to after relative to before (or 1 when before is 0).
+func relativeChange(before int, after int) float64 {
+	return math.Abs(float64(after-before)) / math.Max(float64(before), 1)
+}
+
+// FindJumps flags status regressions and incident counts (total and per rule) that changed
+// by at least threshold (0.5 = 50%) between consecutive runs of the same project. Counts are
+// compared with the last run that has them, skipping runs without counts.
+func FindJumps(records []RunRecord, threshold float64) []Jump {
+	jumps := []Jump{}
+	previous := make(map[string]ProjectRecord)
+	previousCounts := make(map[string]ProjectRecord)
+	for _, record := range records {
+		for _, project := range record.Projects {
+			if last, exists := previous[project.Name]; exists && last.Status == "PASS" && project.Status != "PASS" {
+				jumps = append(jumps, Jump{RunId: record.RunId, Project: project.Name, Reason: JumpStatus,
+					Before: last.Status, After: project.Status})
+			}
+			previous[project.Name] = project
+			if !project.hasCounts() {
+				continue
+			}
+			last, exists := previousCounts[project.Name]
+			previousCounts[project.Name] = project
+			if !exists {
+				continue
+			}
+			if project.Incidents != last.Incidents && relativeChange(last.Incidents, project.Incidents) >= threshold {
+				jumps = append(jumps, Jump{RunId: record.RunId, Project: project.Name, Reason: JumpIncidents,
+					Before: fmt.Sprint(last.Incidents), After: fmt.Sprint(project.Incidents)})
+			}
+			rules := make(map[string]bool)
+			for rule := range last.Rules {
+				rules[rule] = true
+			}
+			for rule := range project.Rules {
+				rules[rule] = true
+			}
+			for _, rule := range slices.Sorted(maps.Keys(rules)) {
+				before, after := last.Rules[rule], project.Rules[rule]
+				if before != after && relativeChange(before, after) >= threshold {
+					jumps = append(jumps, Jump{RunId: record.RunId, Project: project.Name, Rule: rule, Reason: JumpRuleIncidents,
+						Before: fmt.Sprint(before), After: fmt.Sprint(after)})
+				}
+			}
+		}
+	}
+	return jumps
+}
+
+// BuildTrendReport renders a Markdown report of the last runs: pass rates per run and per project,
+// incident counts and durations, and the sudden jumps found by FindJumps.
+func BuildTrendReport(records []RunRecord, lastRuns int, threshold float64) string {
+	if lastRuns > 0 && len(records) > lastRuns {
+		records = records[len(records)-lastRuns:]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# AppCat Trend Report\n\n")
+	if len(records) == 0 {
+		sb.WriteString("No runs recorded.\n")
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf("Runs: %d (%s to %s)\n\n", len(records), records[0].RunId, records[len(records)-1].RunId))
+
+	// Pass rate per run
+	sb.WriteString("## Runs\n\n")
+	sb.WriteString("| Run | Projects | Pass | Fail | Error | Pass Rate | Incidents | Duration (s) |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- | --- | --- | --- |\n")
+	projectNames := []string{}
+	seen := make(map[string]bool)
+	for _, record := range records {
+		counts := make(map[string]int)
+		incidents := 0
+		duration := 0.0
+		for _, project := range record.Projects {
+			counts[project.Status]++
+			if project.Incidents > 0 {
+				incidents += project.Incidents
+			}
+			duration += project.DurationSeconds
+			if !seen[project.Name] {
+				seen[project.Name] = true
+				projectNames = append(projectNames, project.Name)
+			}
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d | %s | %d | %.1f |\n",
+			record.RunId, len(record.Projects), counts["PASS"], counts["FAIL"], counts["ERROR"],
+			passRate(counts["PASS"], len(record.Projects)), incidents, duration))
+	}
+
+	// Status and incidents per project
+	sb.WriteString("\n## Projects\n\n")
+	sb.WriteString("| Project | Pass Rate |")
+	for _, record := range records {
+		sb.WriteString(fmt.Sprintf(" %s |", record.RunId))
+	}
+	sb.WriteString("\n| --- | --- |")
+	sb.WriteString(strings.Repeat(" --- |", len(records)))
+	sb.WriteString("\n")
+	for _, name := range projectNames {
+		passed, total := 0, 0
+		cells := ""
+		for _, record := range records {
+			cell := "-"
+			for _, project := range record.Projects {
+				if project.Name == name {
+					total++
+					if project.Status == "PASS" {
+						passed++
+					}
+					incidents := "-"
+					if project.Incidents >= 0 {
+						incidents = fmt.Sprint(project.Incidents)
+					}
+					cell = fmt.Sprintf("%s %s (%.0fs)", project.Status, incidents, project.DurationSeconds)
+					break
+				}
+			}
+			cells += fmt.Sprintf(" %s |", cell)
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s |%s\n", name, passRate(passed, total), cells))
+	}
+
+	// Sudden jumps
+	sb.WriteString(fmt.Sprintf("\n## Sudden Jumps (>= %.0f%%)\n\n", threshold*100))
+	jumps := FindJumps(records, threshold)
+	if len(jumps) == 0 {
+		sb.WriteString("None.\n")
+	}
+	for _, jump := range jumps {
+		if jump.Rule != "" {
+			sb.WriteString(fmt.Sprintf("- :warning: %s %s %s: %s %s -> %s\n", jump.RunId, jump.Project, jump.Rule, jump.Reason, jump.Before, jump.After))
+		} else {
+			sb.WriteString(fmt.Sprintf("- :warning: %s %s: %s %s -> %s\n", jump.RunId, jump.Project, jump.Reason, jump.Before, jump.After))
+		}
+	}
+	return sb.String()
+}
+
+func passRate(passed int, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", float64(passed)*100/float64(total))
+}
@@ -0,0 +1,36 @@
+package history
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindJumpsSkipsRunsWithoutCounts(t *testing.T) {
+	records := []RunRecord{
+		{RunId: "1", Projects: []ProjectRecord{{Name: "app", Status: "PASS", RunStatus: "COMPLETE", Incidents: 100}}},
+		{RunId: "2", Projects: []ProjectRecord{{Name: "app", Status: "FAIL", RunStatus: runStatusFailed, Incidents: -1}}},
+		{RunId: "3", Projects: []ProjectRecord{{Name: "app", Status: "FAIL", RunStatus: "PARTIAL", Incidents: -1}}},
+		{RunId: "4", Projects: []ProjectRecord{{Name: "app", Status: "FAIL", RunStatus: "COMPLETE", Incidents: 5}}},
+	}
+	want := []Jump{
+		{RunId: "2", Project: "app", Reason: JumpStatus, Before: "PASS", After: "FAIL"},
+		{RunId: "4", Project: "app", Reason: JumpIncidents, Before: "100", After: "5"},
+	}
+	if got := FindJumps(records, 0.5); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindJumps = %+v, want %+v", got, want)
+	}
+}
+
+func TestBuildTrendReportWithoutCounts(t *testing.T) {
+	records := []RunRecord{
+		{RunId: "1", Projects: []ProjectRecord{{Name: "app", Status: "ERROR", RunStatus: runStatusFailed, Incidents: -1}}},
+	}
+	report := BuildTrendReport(records, 0, 0.5)
+	if !strings.Contains(report, "| app | 0% | ERROR - (0s) |") {
+		t.Errorf("missing incidents are not printed as -:\n%s", report)
+	}
+	if strings.Contains(report, "-1") {
+		t.Errorf("report prints -1 incidents:\n%s", report)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"lianwMS/appcat_validation/testcase"
+	"os"
+	"time"
+)
+
+// judgeOptions holds the command line flags configuring the AI judge, shared by the
+// validation run and the eval command.
+type judgeOptions struct {
+	flags        *flag.FlagSet
+	config       testcase.JudgeConfig
+	configFile   *string
+	provider     *string
+	endpoint     *string
+	deployment   *string
+	temperature  *float64
+	timeout      *time.Duration
+	parallelism  *int
+	rateLimit    testcase.RateLimitConfig
+	promptFile   *string
+	contextLines *int
+}
+
+// addJudgeFlags registers the -ai-* judge flags on flags.
+func addJudgeFlags(flags *flag.FlagSet) *judgeOptions {
+	options := &judgeOptions{
+		flags:     flags,
+		config:    testcase.DefaultJudgeConfig(),
+		rateLimit: testcase.RateLimitConfig{InitialBackoff: 2 * time.Second, MaxBackoff: time.Minute},
+	}
+	options.configFile = flags.String("ai-config", "", "AI judge YAML config file (provider, endpoint, deployment, temperature, topP, maxTokens, timeout), overridden by the -ai-* flags set")
+	options.provider = flags.String("ai-provider", string(options.config.Provider), "AI judge provider for the ai action: azure, openai (OpenAI-compatible endpoint) or fake")
+	options.endpoint = flags.String("ai-endpoint", options.config.Endpoint, "AI judge endpoint (Azure OpenAI resource or OpenAI-compatible API base URL)")
+	options.deployment = flags.String("ai-deployment", options.config.Deployment, "AI judge deployment or model name")
+	options.temperature = flags.Float64("ai-temperature", float64(options.config.Temperature), "AI judge sampling temperature")
+	options.timeout = flags.Duration("ai-timeout", options.config.Timeout, "Timeout of each AI judge call, retried like a server error, 0 for no limit")
+	options.parallelism = flags.Int("ai-parallelism", 4, "Number of concurrent AI judge calls")
+	flags.IntVar(&options.rateLimit.RequestsPerMinute, "ai-rpm", 60, "Maximum AI judge requests per minute, 0 for unlimited")
+	flags.IntVar(&options.rateLimit.TokensPerMinute, "ai-tpm", 0, "Maximum estimated AI judge tokens per minute, 0 for unlimited")
+	flags.IntVar(&options.rateLimit.MaxRetries, "ai-retries", 5, "Retries with exponential backoff of AI judge calls throttled (429), failed (5xx) or timed out")
+	options.promptFile = flags.String("ai-prompt", "", "AI review prompt template file (default: built-in prompts/default.yaml)")
+	options.contextLines = flags.Int("ai-context-lines", 0, "Source lines read from the project before and after each incident and added to the AI review prompt")
+	return options
+}
+
+// newJudge creates the rate limited judge and loads the prompt template selected by the flags.
+func (o *judgeOptions) newJudge() (testcase.IncidentJudge, *testcase.PromptTemplate, error) {
+	if *o.configFile != "" {
+		config, err := testcase.LoadJudgeConfig(*o.configFile, o.config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error loading AI judge config: %w", err)
+		}
+		o.config = config
+	}
+	// Flags set on the command line override the config file
+	o.flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "ai-provider":
+			o.config.Provider = testcase.JudgeProvider(*o.provider)
+		case "ai-endpoint":
+			o.config.Endpoint = *o.endpoint
+		case "ai-deployment":
+			o.config.Deployment = *o.deployment
+		case "ai-temperature":
+			o.config.Temperature = float32(*o.temperature)
+		case "ai-timeout":
+			o.config.Timeout = *o.timeout
+		}
+	})
+	o.config.ApiKey = os.Getenv("OPENAI_API_KEY")
+	baseJudge, err := testcase.NewIncidentJudge(o.config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating AI judge: %w", err)
+	}
+
+	var prompt *testcase.PromptTemplate
+	if *o.promptFile != "" {
+		prompt, err = testcase.LoadPromptTemplate(*o.promptFile)
+	} else {
+		prompt, err = testcase.DefaultPromptTemplate()
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("error loading AI prompt template: %w", err)
+	}
+	return testcase.NewRateLimitedJudge(baseJudge, o.rateLimit, int(o.config.MaxTokens)), prompt, nil
+}
@@ -1,20 +1,72 @@
 package logger
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
+	"runtime"
+	"strings"
 	"sync"
+	"time"
 )
 
+// Format selects how log records are written.
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+// Keys of the fields attached to log records with Logger.With.
+const (
+	KeyProject = "project"
+	KeyAction  = "action"
+	KeyRuleSet = "ruleset"
+	KeyRule    = "rule"
+)
+
+// Options configures the global logger.
+type Options struct {
+	Level  slog.Level
+	Format Format
+}
+
+// Logger writes leveled log records with key-value fields.
+type Logger struct {
+	slog    *slog.Logger
+	options Options
+}
+
 var (
-	logger  *log.Logger
+	logger  *Logger
 	once    sync.Once
 	logFile *os.File
 )
 
+// ParseLevel parses debug, info, warn or error.
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return level, fmt.Errorf("unknown log level '%s', expected debug, info, warn or error", name)
+	}
+	return level, nil
+}
+
+// ParseFormat parses text or json.
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case FormatText, FormatJSON:
+		return Format(name), nil
+	}
+	return "", fmt.Errorf("unknown log format '%s', expected %s or %s", name, FormatText, FormatJSON)
+}
+
 // Init initializes the global logger with a custom log file name and multi-writer support.
-func Init(logFileName string, writeToConsole bool) error {
+func Init(logFileName string, writeToConsole bool, options Options) error {
 	var err error
 	once.Do(func() {
 		// Open log file
@@ -32,13 +84,32 @@ func Init(logFileName string, writeToConsole bool) error {
 		multiWriter := io.MultiWriter(writers...)
 
 		// Initialize the logger
-		logger = log.New(multiWriter, "[GLOBAL] ", log.Ldate|log.Ltime|log.Lshortfile)
+		logger = New(multiWriter, options)
 	})
 	return err
 }
 
+// InitWriter initializes the global logger writing to w only, for commands without a log file.
+func InitWriter(w io.Writer, options Options) {
+	once.Do(func() {
+		logger = New(w, options)
+	})
+}
+
+// New creates a logger writing to w, independent of the global logger.
+func New(w io.Writer, options Options) *Logger {
+	return &Logger{slog: slog.New(newHandler(w, options, "GLOBAL")), options: options}
+}
+
+func newHandler(w io.Writer, options Options, name string) slog.Handler {
+	if options.Format == FormatJSON {
+		return slog.NewJSONHandler(w, &slog.HandlerOptions{AddSource: true, Level: options.Level})
+	}
+	return &textHandler{writer: w, mutex: &sync.Mutex{}, level: options.Level, prefix: fmt.Sprintf("[%s] ", name)}
+}
+
 // Get returns the global logger
-func Get() *log.Logger {
+func Get() *Logger {
 	if logger == nil {
 		panic("logger not initialized: call logger.Init first")
 	}
@@ -52,3 +123,189 @@ func CloseLogFile() error {
 	}
 	return nil
 }
+
+// With returns a child logger adding the key-value pairs to every record, e.g.
+// With(logger.KeyProject, name).
+func (l *Logger) With(args ...any) *Logger {
+	return &Logger{slog: l.slog.With(args...), options: l.options}
+}
+
+// WithOutput returns a child logger also writing its records to w, in the same format and
+// level, with the "[GLOBAL]" text prefix replaced by name. Fields added to l before are not
+// written to w, add them to the child instead.
+func (l *Logger) WithOutput(w io.Writer, name string) *Logger {
+	handler := &fanoutHandler{handlers: []slog.Handler{l.slog.Handler(), newHandler(w, l.options, name)}}
+	return &Logger{slog: slog.New(handler), options: l.options}
+}
+
+// WithProject returns a child logger adding the project field.
+func (l *Logger) WithProject(project string) *Logger {
+	return l.With(KeyProject, project)
+}
+
+// WithAction returns a child logger adding the action field.
+func (l *Logger) WithAction(action string) *Logger {
+	return l.With(KeyAction, action)
+}
+
+// WithRule returns a child logger adding the ruleset and rule fields.
+func (l *Logger) WithRule(ruleSet string, rule string) *Logger {
+	if rule == "" {
+		return l.With(KeyRuleSet, ruleSet)
+	}
+	return l.With(KeyRuleSet, ruleSet, KeyRule, rule)
+}
+
+// Enabled reports whether records of level are written.
+func (l *Logger) Enabled(level slog.Level) bool {
+	return l.slog.Enabled(context.Background(), level)
+}
+
+func (l *Logger) log(level slog.Level, message string) {
+	if !l.Enabled(level) {
+		return
+	}
+	// Skip runtime.Callers, log and the exported method to report the caller's location
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	record := slog.NewRecord(time.Now(), level, strings.TrimRight(message, "\n"), pcs[0])
+	l.slog.Handler().Handle(context.Background(), record)
+}
+
+// Debugf logs per-item trace details, hidden unless -log-level is debug.
+func (l *Logger) Debugf(format string, args ...any) {
+	l.log(slog.LevelDebug, fmt.Sprintf(format, args...))
+}
+
+// Infof logs progress and results.
+func (l *Logger) Infof(format string, args ...any) {
+	l.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+// Warnf logs unexpected conditions the run recovers from.
+func (l *Logger) Warnf(format string, args ...any) {
+	l.log(slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+// Errorf logs failures of an action or of the run.
+func (l *Logger) Errorf(format string, args ...any) {
+	l.log(slog.LevelError, fmt.Sprintf(format, args...))
+}
+
+// Printf logs at info level, like Infof.
+func (l *Logger) Printf(format string, args ...any) {
+	l.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+// Print logs at info level, like Infof.
+func (l *Logger) Print(args ...any) {
+	l.log(slog.LevelInfo, fmt.Sprint(args...))
+}
+
+// fanoutHandler sends every record to all its handlers.
+type fanoutHandler struct {
+	handlers []slog.Handler
+}
+
+func (h *fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *fanoutHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, record.Level) {
+			errs = append(errs, handler.Handle(ctx, record.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	child := &fanoutHandler{}
+	for _, handler := range h.handlers {
+		child.handlers = append(child.handlers, handler.WithAttrs(attrs))
+	}
+	return child
+}
+
+func (h *fanoutHandler) WithGroup(name string) slog.Handler {
+	child := &fanoutHandler{}
+	for _, handler := range h.handlers {
+		child.handlers = append(child.handlers, handler.WithGroup(name))
+	}
+	return child
+}
+
+// textHandler writes records in the historical log.Logger layout, with the level after the
+// time and the fields at the end: "[GLOBAL] 2006/01/02 15:04:05 INFO file.go:10: message key=value".
+type textHandler struct {
+	writer io.Writer
+	mutex  *sync.Mutex
+	level  slog.Level
+	prefix string
+	fields string
+	group  string
+}
+
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *textHandler) Handle(_ context.Context, record slog.Record) error {
+	var line strings.Builder
+	line.WriteString(h.prefix)
+	line.WriteString(record.Time.Format("2006/01/02 15:04:05 "))
+	line.WriteString(record.Level.String())
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		line.WriteString(fmt.Sprintf(" %s:%d", frame.File[strings.LastIndex(frame.File, "/")+1:], frame.Line))
+	}
+	line.WriteString(": ")
+	line.WriteString(record.Message)
+	line.WriteString(h.fields)
+	record.Attrs(func(attr slog.Attr) bool {
+		line.WriteString(h.formatAttr(attr))
+		return true
+	})
+	line.WriteString("\n")
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	_, err := io.WriteString(h.writer, line.String())
+	return err
+}
+
+func (h *textHandler) formatAttr(attr slog.Attr) string {
+	key := attr.Key
+	if h.group != "" {
+		key = h.group + "." + key
+	}
+	value := attr.Value.Resolve().String()
+	if strings.ContainsAny(value, " \t\n\"=") {
+		value = fmt.Sprintf("%q", value)
+	}
+	return fmt.Sprintf(" %s=%s", key, value)
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	child := *h
+	for _, attr := range attrs {
+		child.fields += h.formatAttr(attr)
+	}
+	return &child
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	child := *h
+	if child.group != "" {
+		name = child.group + "." + name
+	}
+	child.group = name
+	return &child
+}
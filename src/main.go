@@ -1,12 +1,18 @@
 package main
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"lianwMS/appcat_validation/history"
 	"lianwMS/appcat_validation/logger"
 	"lianwMS/appcat_validation/testcase"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -16,9 +22,35 @@ const (
 	LogExtension        string = ".log"
 	TestResultExtension string = ".md"
 	CSVExtension        string = ".csv"
+	JSONExtension       string = ".json"
+)
+
+const (
+	coverageFilePrefix string = "appcat_coverage"
+	scoreFilePrefix    string = "appcat_scores"
+)
+
+// Process exit codes
+const (
+	ExitPass           int = 0
+	ExitValidationFail int = 1
+	ExitInfrastructure int = 2
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "trend" {
+		runTrend(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "eval" {
+		runEval(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "query" {
+		runQuery(os.Args[2:])
+		return
+	}
+
 	// Mock input parameters for testing purposes
 	wd, _ := os.Getwd()
 	appcatAppFolder := flag.String("appcat", `C:\Users\lianw\sampleRepo\azure-migrate-appcat-for-java-cli-windows-amd64-7.6.0.6-preview`, "Path to AppCat application folder")
@@ -26,25 +58,111 @@ func main() {
 	baselineFolder := flag.String("baseline", filepath.Join(wd, "..", "data", "baseline"), "Path to baseline folder")
 	outputFolder := flag.String("output", filepath.Join(wd, "..", "testResults"), "Path to output folder")
 	repoListFile := flag.String("target", filepath.Join(wd, "TargetCatalog", "CI"), "Target projects list")
+	maxFailures := flag.Int("max-failures", 0, "Number of projects allowed to fail validation before exiting with a failure code")
+	maxDiffPercent := flag.Float64("max-diff-percent", 0, "Failing projects whose diffs are at most this percentage of their incidents are tolerated")
+	actions := flag.String("actions", "run,validate", "Comma separated actions to perform: run, analyze, validate, heuristics, ai")
+	existingOutputFolder := flag.String("existing", "", "Path to pre-existing AppCat output (<existing>/<target>/appcat_output) to use instead of running AppCat")
+	judgeOptions := addJudgeFlags(flag.CommandLine)
+	aiScope := flag.String("ai-scope", string(testcase.AIReviewDiff), "Incidents reviewed by the ai action: diff (new and changed incidents) or all")
+	aiCacheFolder := flag.String("ai-cache", "", "Folder of the AI verdict cache (default <output>/ai_verdict_cache), 'none' to disable")
+	stderrTailLines := flag.Int("stderr-lines", 20, "Last lines of AppCat stderr included in the report when AppCat fails")
+	failOnLogRegression := flag.Bool("fail-on-log-regression", false, "Fail validation when analysis.log error, warning or signature counts exceed the baseline, instead of only marking them")
+	parseCacheFolder := flag.String("parse-cache", "", "Folder caching parsed AppCat outputs between runs, by output file hash (disabled when empty)")
+	logLevel, logFormat := addLogFlags(flag.CommandLine)
+	sarifMode := flag.String("sarif", string(testcase.SarifNone), "Export SARIF per project: none, all (every incident) or diff (validation diffs only)")
 	flag.Parse()
 
+	switch testcase.SarifMode(*sarifMode) {
+	case testcase.SarifNone, testcase.SarifAll, testcase.SarifDiff:
+	default:
+		fmt.Printf("Invalid -sarif value '%s': expected none, all or diff\n", *sarifMode)
+		os.Exit(ExitInfrastructure)
+	}
+
+	logOptions, err := parseLogOptions(*logLevel, *logFormat)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(ExitInfrastructure)
+	}
+
+	actionList, err := parseActions(*actions)
+	if err != nil {
+		fmt.Printf("Invalid -actions value: %v\n", err)
+		os.Exit(ExitInfrastructure)
+	}
+	if testcase.SarifMode(*sarifMode) == testcase.SarifDiff && !slices.Contains(actionList, testcase.ActionValidate) {
+		fmt.Printf("-sarif %s requires action '%s'\n", testcase.SarifDiff, testcase.ActionValidate)
+		os.Exit(ExitInfrastructure)
+	}
+	if *existingOutputFolder != "" && slices.Contains(actionList, testcase.ActionRun) {
+		fmt.Printf("Action '%s' cannot be combined with -existing\n", testcase.ActionRun)
+		os.Exit(ExitInfrastructure)
+	}
+
+	if *stderrTailLines < 0 {
+		fmt.Printf("Invalid -stderr-lines value %d: expected 0 or more\n", *stderrTailLines)
+		os.Exit(ExitInfrastructure)
+	}
+
+	switch testcase.AIReviewScope(*aiScope) {
+	case testcase.AIReviewDiff, testcase.AIReviewAll:
+	default:
+		fmt.Printf("Invalid -ai-scope value '%s': expected diff or all\n", *aiScope)
+		os.Exit(ExitInfrastructure)
+	}
+	if testcase.AIReviewScope(*aiScope) == testcase.AIReviewDiff && slices.Contains(actionList, testcase.ActionAIReview) &&
+		!slices.Contains(actionList, testcase.ActionValidate) {
+		fmt.Printf("-ai-scope %s requires action '%s', use -ai-scope %s to review every incident\n", testcase.AIReviewDiff, testcase.ActionValidate, testcase.AIReviewAll)
+		os.Exit(ExitInfrastructure)
+	}
+
+	var judge testcase.IncidentJudge
+	var prompt *testcase.PromptTemplate
+	if slices.Contains(actionList, testcase.ActionAIReview) {
+		judge, prompt, err = judgeOptions.newJudge()
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(ExitInfrastructure)
+		}
+	}
+
 	// Initialize testing environment
-	targetList, err := initTesting(*appcatAppFolder, *sourceRepoFolder, *baselineFolder, *outputFolder, *repoListFile)
+	targetList, err := initTesting(*appcatAppFolder, *sourceRepoFolder, *baselineFolder, *outputFolder, *repoListFile, *existingOutputFolder, actionList)
 	if err != nil {
 		fmt.Printf("Error initializing testing: %v\n", err)
-		os.Exit(1)
+		os.Exit(ExitInfrastructure)
+	}
+
+	var verdictCache *testcase.VerdictCache
+	if judge != nil && *aiCacheFolder != "none" {
+		if *aiCacheFolder == "" {
+			*aiCacheFolder = filepath.Join(*outputFolder, "ai_verdict_cache")
+		}
+		verdictCache, err = testcase.NewVerdictCache(*aiCacheFolder)
+		if err != nil {
+			fmt.Printf("Error opening AI verdict cache: %v\n", err)
+			os.Exit(ExitInfrastructure)
+		}
+	}
+
+	var outputCache *testcase.OutputCache
+	if *parseCacheFolder != "" {
+		outputCache, err = testcase.NewOutputCache(*parseCacheFolder)
+		if err != nil {
+			fmt.Printf("Error opening output cache: %v\n", err)
+			os.Exit(ExitInfrastructure)
+		}
 	}
 
 	// Initialize logger
 	var timeInFileName = time.Now().Format("20060102_150405")
 	var globalFilePrefix string = "appcat_test"
 	logFilePath := filepath.Join(*outputFolder, fmt.Sprintf("%s_%s%s", globalFilePrefix, timeInFileName, LogExtension))
-	err = logger.Init(logFilePath, true)
+	err = logger.Init(logFilePath, true, logOptions)
 	if err != nil {
 		fmt.Printf("Failed to initialize logger: %v\n", err)
-		os.Exit(1)
+		os.Exit(ExitInfrastructure)
 	}
-	defer logger.CloseLogFile()
 	var logger = logger.Get()
 
 	// Now use *appcatApplicationFolder, *testDataFolder, *testOutputFolder, *targetProject as your variables
@@ -54,123 +172,360 @@ func main() {
 	logger.Printf("Output Folder: %s", *outputFolder)
 	logger.Printf("Target Projects List: %s", *repoListFile)
 	logger.Printf("Target Projects Found: %s", strings.Join(targetList, "\n"))
+	logger.Printf("Existing Output Folder: %s", *existingOutputFolder)
+	logger.Printf("Actions: %v", actionList)
+	logger.Printf("SARIF Export: %s", *sarifMode)
+	if outputCache != nil {
+		logger.Printf("Output Cache: %s", *parseCacheFolder)
+	}
+	if judge != nil {
+		logger.Printf("AI Judge: %s (%s), scope: %s, cache: %s, prompt: %s, context lines: %d", judge.Name(), judgeOptions.config.Endpoint, *aiScope, *aiCacheFolder, prompt.ID(), *judgeOptions.contextLines)
+	}
 
-	actionList := []testcase.ActionType{testcase.ActionRun, testcase.ActionValidate}
 	testCases := []testcase.TestCase{}
 
 	// Initialize test case
 	for _, target := range targetList {
 		testCase := testcase.TestCase{
-			Name:              target,
-			ApplicationFolder: *appcatAppFolder,
-			ProjectFolder:     filepath.Join(*sourceRepoFolder, target),
-			BaseLineFolder:    filepath.Join(*baselineFolder, target, "appcat_output"),
-			OutputFolder:      filepath.Join(*outputFolder, target),
-			ActionList:        actionList,
-		}
-		logger.Printf("%s Created", testCase.GetInfo())
+			Name:                target,
+			ApplicationFolder:   *appcatAppFolder,
+			ProjectFolder:       filepath.Join(*sourceRepoFolder, target),
+			BaseLineFolder:      filepath.Join(*baselineFolder, target, "appcat_output"),
+			OutputFolder:        filepath.Join(*outputFolder, target),
+			ActionList:          actionList,
+			SarifMode:           testcase.SarifMode(*sarifMode),
+			Judge:               judge,
+			AIReviewScope:       testcase.AIReviewScope(*aiScope),
+			AIParallelism:       *judgeOptions.parallelism,
+			VerdictCache:        verdictCache,
+			Prompt:              prompt,
+			PromptContextLines:  *judgeOptions.contextLines,
+			StderrTailLines:     *stderrTailLines,
+			OutputCache:         outputCache,
+			FailOnLogRegression: *failOnLogRegression,
+		}
+		if *existingOutputFolder != "" {
+			testCase.ExistingOutputFolder = filepath.Join(*existingOutputFolder, target, "appcat_output")
+		}
+		logger.Debugf("%s Created", testCase.GetInfo())
 		testCases = append(testCases, testCase)
 	}
 	logger.Printf("Total Test Cases: %d", len(testCases))
 
-	fullResults := make(map[string]string)
+	results := []testcase.TestResult{}
 	fullIncidentsCount := 0
-	fullIncidentDetails := make(map[string](map[string]int))
+	fullRuleIncidents := make(map[string]testcase.RuleCounts)
+	fullRuleDiffs := make(map[string]testcase.RuleCounts)
+	fullCoverage := make(map[string]testcase.ProjectCoverage)
+	missingCoverage := make(map[string]string)
+	fullScores := make(map[string][]testcase.ScoreRow)
+	runRecord := history.RunRecord{RunId: timeInFileName, Time: time.Now()}
 	for _, testCase := range testCases {
-		logger.Printf("Processing Test Case: %s", testCase.Name)
-		resultMessage, resultCount, resultDetails, caseErr := testCase.Run()
+		logger.WithProject(testCase.Name).Infof("Processing Test Case: %s", testCase.Name)
+		result, caseErr := testCase.Run()
 		if caseErr != nil {
-			logger.Printf("Error running test case %s: %v", testCase.Name, caseErr)
-			fullResults[testCase.Name] = fmt.Sprintf("Error: %v", caseErr)
+			logger.WithProject(testCase.Name).Errorf("Error running test case %s: %v", testCase.Name, caseErr)
+			result.Message = testcase.FailureMessage(testCase.Name, caseErr)
 		}
-		fullResults[testCase.Name] = resultMessage
-		if resultCount >= 0 {
-			fullIncidentsCount += resultCount
+		results = append(results, result)
+		if result.IncidentsCount >= 0 {
+			fullIncidentsCount += result.IncidentsCount
 		}
-		if resultDetails != nil {
-			fullIncidentDetails[testCase.Name] = resultDetails
+		if result.RuleIncidents != nil {
+			fullRuleIncidents[testCase.Name] = result.RuleIncidents
 		}
-		logger.Printf("Completed Test Case: %s", testCase.Name)
+		if result.Diffs != nil {
+			fullRuleDiffs[testCase.Name] = testcase.CountDiffRules(result.Diffs)
+		}
+		if result.Coverage != nil {
+			fullCoverage[testCase.Name] = result.Coverage
+		} else if caseErr != nil {
+			missingCoverage[testCase.Name] = caseErr.Error()
+		} else {
+			missingCoverage[testCase.Name] = fmt.Sprintf("no %s, run status %s", testcase.OutputFileName, result.RunStatus)
+		}
+		if result.Score != nil {
+			fullScores[testCase.Name] = testcase.ScoreRows(result.Score, result.BaselineScore)
+		}
+		logErrors := -1
+		if result.LogSummary != nil {
+			logErrors = result.LogSummary.Errors
+		}
+		runRecord.Projects = append(runRecord.Projects, history.ProjectRecord{
+			Name:            testCase.Name,
+			Status:          string(result.Status),
+			RunStatus:       string(result.RunStatus),
+			Incidents:       result.IncidentsCount,
+			Rules:           result.RuleDetails,
+			Diffs:           len(result.Diffs),
+			DurationSeconds: result.Duration.Seconds(),
+			LogErrors:       logErrors,
+		})
+		logger.WithProject(testCase.Name).Infof("Completed Test Case: %s", testCase.Name)
+	}
+
+	// Append this run to the history used by the trend command
+	if err := history.Append(history.GetHistoryFile(*outputFolder), runRecord); err != nil {
+		logger.Warnf("Failed to append run history: %v", err)
 	}
 
 	// Testoutput file path
 	resultFilePath := filepath.Join(*outputFolder, fmt.Sprintf("%s_%s%s", globalFilePrefix, timeInFileName, TestResultExtension))
 
+	passed, failed, errored, tolerated := summarizeResults(results, *maxDiffPercent)
+	summaryLine := fmt.Sprintf("Summary: %d projects, %d passed, %d failed (%d tolerated), %d errors", len(results), passed, failed, tolerated, errored)
+	if slices.Contains(actionList, testcase.ActionHeuristics) {
+		flagged := 0
+		for _, result := range results {
+			flagged += len(result.Findings)
+		}
+		summaryLine += fmt.Sprintf(", %d incidents flagged by heuristics", flagged)
+	}
+	if slices.Contains(actionList, testcase.ActionAIReview) {
+		reviewed, rejected, reviewFailed := 0, 0, 0
+		for _, result := range results {
+			for _, verdict := range result.Verdicts {
+				switch {
+				case verdict.Failed():
+					reviewFailed++
+				case verdict.IsFalsePositive():
+					reviewed++
+					rejected++
+				default:
+					reviewed++
+				}
+			}
+		}
+		summaryLine += fmt.Sprintf(", %d of %d reviewed incidents rejected by AI (%d reviews failed)", rejected, reviewed, reviewFailed)
+		if verdictCache != nil {
+			hits, misses := verdictCache.Stats()
+			logger.Printf("AI verdict cache: %d hits, %d misses", hits, misses)
+		}
+	}
+	if outputCache != nil {
+		hits, misses := outputCache.Stats()
+		logger.Printf("Output cache: %d hits, %d misses", hits, misses)
+	}
+	logger.Print(summaryLine)
+
 	// Write test results to output file
 	testOutputFile, err := os.Create(resultFilePath)
 	if err != nil {
-		logger.Fatalf("Failed to create test output file: %v", err)
+		logger.Errorf("Failed to create test output file: %v", err)
+		exit(ExitInfrastructure)
 	}
 	defer testOutputFile.Close()
-	// Write header
-	testOutputFile.WriteString("# AppCat Test Results\n")
-	for _, result := range fullResults {
-		testOutputFile.WriteString(result + "\n")
+	if err := testcase.WriteResultsReport(testOutputFile, summaryLine, results); err != nil {
+		logger.Errorf("Failed to write test output file: %v", err)
+		exit(ExitInfrastructure)
 	}
 
-	// If actionList contains ActionAnalyze, generate a summary report
+	// Rule x project pivots of the incidents and, when validation ran, of the diffs
 	if fullIncidentsCount > 0 {
 		logger.Printf("Total incidents found across all projects: %d", fullIncidentsCount)
+	}
+	if len(fullRuleIncidents) > 0 {
+		summaryFilePath := filepath.Join(*outputFolder, fmt.Sprintf("%s_%s%s", globalFilePrefix, timeInFileName, CSVExtension))
+		if err := writeCSV(summaryFilePath, testcase.RulePivot(fullRuleIncidents, targetList)); err != nil {
+			logger.Errorf("Failed to write summary file: %v", err)
+			exit(ExitInfrastructure)
+		}
+		logger.Printf("Global summary written to: %s\n", summaryFilePath)
+	}
+	if slices.Contains(actionList, testcase.ActionValidate) && len(fullRuleDiffs) > 0 {
+		diffsFilePath := filepath.Join(*outputFolder, fmt.Sprintf("%s_%s_diffs%s", globalFilePrefix, timeInFileName, CSVExtension))
+		if err := writeCSV(diffsFilePath, testcase.RulePivot(fullRuleDiffs, targetList)); err != nil {
+			logger.Errorf("Failed to write diffs summary file: %v", err)
+			exit(ExitInfrastructure)
+		}
+		logger.Printf("Global diffs summary written to: %s\n", diffsFilePath)
+	}
 
-		rules := make(map[string]int)
-		for _, targetHash := range fullIncidentDetails {
-			for rule := range targetHash {
-				if _, exists := rules[rule]; !exists {
-					rules[rule] = targetHash[rule]
-				} else {
-					rules[rule] += targetHash[rule]
-				}
+	// Migration scores of the projects analyzed in this run, with their baselines
+	if slices.Contains(actionList, testcase.ActionAnalyze) && len(fullScores) > 0 {
+		if err := writeScores(fullScores, *outputFolder, fmt.Sprintf("%s_%s", scoreFilePrefix, timeInFileName)); err != nil {
+			logger.Errorf("Failed to write migration scores: %v", err)
+			exit(ExitInfrastructure)
+		}
+	}
+
+	// Rule coverage across the projects analyzed in this run
+	if slices.Contains(actionList, testcase.ActionAnalyze) && len(fullCoverage)+len(missingCoverage) > 0 {
+		if err := writeCoverageReport(fullCoverage, missingCoverage, *outputFolder, fmt.Sprintf("%s_%s", coverageFilePrefix, timeInFileName)); err != nil {
+			logger.Errorf("Failed to write coverage report: %v", err)
+			exit(ExitInfrastructure)
+		}
+	}
+
+	// Closing files explicitly, deferred calls do not run on os.Exit
+	testOutputFile.Close()
+	switch {
+	case errored > 0:
+		exit(ExitInfrastructure)
+	case failed-tolerated > *maxFailures:
+		exit(ExitValidationFail)
+	default:
+		exit(ExitPass)
+	}
+}
+
+// writeCSV writes the rows to the CSV file filePath.
+func writeCSV(filePath string, rows [][]string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := testcase.WriteCSV(file, rows); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// writeScores writes the migration score rows of the projects as CSV, one row per project and
+// scope, and as JSON named fileName in outputFolder.
+func writeScores(scores map[string][]testcase.ScoreRow, outputFolder string, fileName string) error {
+	csvFilePath := filepath.Join(outputFolder, fileName+CSVExtension)
+	csvFile, err := os.Create(csvFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to create scores file: %w", err)
+	}
+	defer csvFile.Close()
+	writer := csv.NewWriter(csvFile)
+	writer.Write([]string{"Project", "Scope", "Name", "Incidents", "StoryPoints", "BaselineIncidents", "BaselineStoryPoints", "Changed"})
+	for _, project := range slices.Sorted(maps.Keys(scores)) {
+		for _, row := range scores[project] {
+			baselineIncidents, baselineStoryPoints := "", ""
+			if row.Baseline != nil {
+				baselineIncidents, baselineStoryPoints = strconv.Itoa(row.Baseline.Incidents), strconv.Itoa(row.Baseline.StoryPoints)
 			}
+			writer.Write([]string{project, row.Scope, row.Name, strconv.Itoa(row.Score.Incidents), strconv.Itoa(row.Score.StoryPoints),
+				baselineIncidents, baselineStoryPoints, strconv.FormatBool(row.Changed())})
 		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write scores file: %w", err)
+	}
 
-		summaryFilePath := filepath.Join(*outputFolder, fmt.Sprintf("%s_%s%s", globalFilePrefix, timeInFileName, CSVExtension))
-		summaryFile, err := os.Create(summaryFilePath)
-		if err != nil {
-			logger.Fatalf("Failed to create summary file: %v", err)
-		}
-		defer summaryFile.Close()
-
-		targetNameList := strings.Join(targetList, ",")
-		summaryFile.WriteString(fmt.Sprintf("Rule,%s\n", targetNameList))
-		for rule, _ := range rules {
-			rowValue := rule
-			for _, target := range targetList {
-				if targetHash, exists := fullIncidentDetails[target]; exists {
-					if count, exists := targetHash[rule]; exists {
-						rowValue += fmt.Sprintf(",%d", count)
-					} else {
-						rowValue += ", "
-					}
-				} else {
-					rowValue += ", "
-				}
+	data, err := json.MarshalIndent(scores, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal scores: %w", err)
+	}
+	jsonFilePath := filepath.Join(outputFolder, fileName+JSONExtension)
+	if err := os.WriteFile(jsonFilePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write scores file: %w", err)
+	}
+	logger.Get().Printf("[Score] Migration scores written to: %s, %s", csvFilePath, jsonFilePath)
+	return nil
+}
+
+// writeCoverageReport writes the rule coverage of the projects as a Markdown report and a CSV
+// matrix named fileName in outputFolder. Projects without coverage are listed in the report.
+func writeCoverageReport(coverage map[string]testcase.ProjectCoverage, missing map[string]string, outputFolder string, fileName string) error {
+	projects := slices.Sorted(maps.Keys(coverage))
+	matrix := testcase.BuildCoverageMatrix(coverage)
+
+	reportFilePath := filepath.Join(outputFolder, fileName+TestResultExtension)
+	if err := os.WriteFile(reportFilePath, []byte(testcase.BuildCoverageReport(matrix, projects, missing)), 0644); err != nil {
+		return fmt.Errorf("failed to write coverage report: %w", err)
+	}
+	csvFilePath := filepath.Join(outputFolder, fileName+CSVExtension)
+	if err := writeCSV(csvFilePath, testcase.CoverageCSV(matrix, projects)); err != nil {
+		return fmt.Errorf("failed to write coverage matrix: %w", err)
+	}
+	logger.Get().Printf("[Coverage] %d rules, report written to: %s, matrix written to: %s", len(matrix), reportFilePath, csvFilePath)
+	return nil
+}
+
+// summarizeResults counts passed, failed and errored projects. A project failed only by its diffs
+// is tolerated when they are at most maxDiffPercent of its incidents.
+func summarizeResults(results []testcase.TestResult, maxDiffPercent float64) (int, int, int, int) {
+	passed, failed, errored, tolerated := 0, 0, 0, 0
+	for _, result := range results {
+		switch result.Status {
+		case testcase.StatusPass:
+			passed++
+		case testcase.StatusFail:
+			failed++
+			if maxDiffPercent > 0 && result.FailedOnlyBy(testcase.FailureDiffs) && result.IncidentsCount > 0 &&
+				float64(len(result.Diffs))*100/float64(result.IncidentsCount) <= maxDiffPercent {
+				tolerated++
 			}
-			summaryFile.WriteString(fmt.Sprintf("%s\n", rowValue))
+		default:
+			errored++
 		}
+	}
+	return passed, failed, errored, tolerated
+}
+
+// exit closes the global log file and terminates the process with the given code.
+func exit(code int) {
+	logger.Get().Printf("Exit code: %d", code)
+	logger.CloseLogFile()
+	os.Exit(code)
+}
 
-		// for rule, targetHash := range fullIncidentDetails {
-		// 	rowValue := rule
-		// 	for _, target := range targetList {
-		// 		if count, exists := targetHash[target]; exists {
-		// 			rowValue += fmt.Sprintf(",%d", count)
-		// 		} else {
-		// 			rowValue += ",0"
-		// 		}
-		// 	}
+// addLogFlags registers the -log-level and -log-format flags on flags.
+func addLogFlags(flags *flag.FlagSet) (*string, *string) {
+	level := flags.String("log-level", "info", "Minimum log level: debug, info, warn or error")
+	format := flags.String("log-format", string(logger.FormatText), "Log format: text or json (one JSON object per line)")
+	return level, format
+}
 
-		logger.Printf("[Analyze] Global summary written to: %s\n", summaryFilePath)
+// parseLogOptions validates the -log-level and -log-format values.
+func parseLogOptions(level string, format string) (logger.Options, error) {
+	options := logger.Options{}
+	var err error
+	if options.Level, err = logger.ParseLevel(level); err != nil {
+		return options, fmt.Errorf("invalid -log-level value: %w", err)
+	}
+	if options.Format, err = logger.ParseFormat(format); err != nil {
+		return options, fmt.Errorf("invalid -log-format value: %w", err)
+	}
+	return options, nil
+}
+
+// parseActions parses a comma separated list of action names.
+func parseActions(actions string) ([]testcase.ActionType, error) {
+	actionList := []testcase.ActionType{}
+	for _, name := range strings.Split(actions, ",") {
+		action := testcase.ActionType(strings.TrimSpace(name))
+		if action == "" {
+			continue
+		}
+		if !slices.Contains(testcase.AllActions, action) {
+			return nil, fmt.Errorf("unknown action '%s', expected one of %v", action, testcase.AllActions)
+		}
+		if !slices.Contains(actionList, action) {
+			actionList = append(actionList, action)
+		}
 	}
+	if len(actionList) == 0 {
+		return nil, fmt.Errorf("no action specified")
+	}
+	return actionList, nil
 }
 
-func initTesting(appcatAppFolder string, sourceRepoFolder string, baselineFolder string, outputFolder string, repoListFile string) ([]string, error) {
-	// Verifty appcatAppFolder
-	if _, err := os.Stat(appcatAppFolder); os.IsNotExist(err) {
-		return nil, fmt.Errorf("the application folder path '%s' does not exist", appcatAppFolder)
+func initTesting(appcatAppFolder string, sourceRepoFolder string, baselineFolder string, outputFolder string, repoListFile string, existingOutputFolder string, actionList []testcase.ActionType) ([]string, error) {
+	// AppCat and the source repos are only needed when AppCat is run
+	if slices.Contains(actionList, testcase.ActionRun) {
+		// Verifty appcatAppFolder
+		if _, err := os.Stat(appcatAppFolder); os.IsNotExist(err) {
+			return nil, fmt.Errorf("the application folder path '%s' does not exist", appcatAppFolder)
+		}
+
+		// Verify sourceRepoFolder
+		if _, err := os.Stat(sourceRepoFolder); os.IsNotExist(err) {
+			return nil, fmt.Errorf("the source repo folder path '%s' does not exist", sourceRepoFolder)
+		}
 	}
 
-	// Verify sourceRepoFolder
-	if _, err := os.Stat(sourceRepoFolder); os.IsNotExist(err) {
-		return nil, fmt.Errorf("the source repo folder path '%s' does not exist", sourceRepoFolder)
+	// Verify existingOutputFolder
+	if existingOutputFolder != "" {
+		if _, err := os.Stat(existingOutputFolder); os.IsNotExist(err) {
+			return nil, fmt.Errorf("the existing output folder path '%s' does not exist", existingOutputFolder)
+		}
 	}
 
 	// Verify baselineFolder
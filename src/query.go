@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"lianwMS/appcat_validation/logger"
+	"lianwMS/appcat_validation/testcase"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats of the query command
+const (
+	QueryFormatTable string = "table"
+	QueryFormatJSON  string = "json"
+	QueryFormatCSV   string = "csv"
+)
+
+// queryMessageWidth is the width of the message column of the table format.
+const queryMessageWidth = 80
+
+// runQuery implements the "query" command: it loads the incident store written by the analyze
+// action for a project, or builds one from an AppCat output or baseline, and prints the incidents
+// matching the filters as a table, JSON or CSV.
+func runQuery(args []string) {
+	wd, _ := os.Getwd()
+	flags := flag.NewFlagSet("query", flag.ExitOnError)
+	project := flags.String("project", "", "Project name, as in the target list (required)")
+	outputFolder := flags.String("output", filepath.Join(wd, "..", "testResults"), "Path to test results folder, whose incident store (<output>/<project>/analysis_output/"+testcase.IncidentsFileName+") is queried by default")
+	baselineFolder := flags.String("baseline", filepath.Join(wd, "..", "data", "baseline"), "Path to baseline folder, queried when set or when the project has no incident store")
+	existingOutputFolder := flags.String("existing", "", "Path to pre-existing AppCat output (<existing>/<project>/appcat_output) to query")
+	outputDir := flags.String("dir", "", "AppCat output folder, or folder of an incident store, to query")
+	ruleSet := flags.String("ruleset", "", "Ruleset name")
+	rule := flags.String("rule", "", "Regular expression matched against rule IDs")
+	category := flags.String("category", "", "Rule category, e.g. mandatory, optional or potential")
+	effort := flags.String("effort", "", "Effort: N, N-M, N- (at least N) or -M (at most M)")
+	label := flags.String("label", "", "Rule label, e.g. konveyor.io/target=azure-aks")
+	file := flags.String("file", "", "Glob matched against incident files relative to the project, or file names when it has no '/'")
+	message := flags.String("message", "", "Text contained in the incident message, case-insensitive")
+	format := flags.String("format", QueryFormatTable, "Output format: table, json or csv")
+	logLevel, logFormat := addLogFlags(flags)
+	flags.Parse(args)
+
+	fail := func(format string, args ...any) {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+		os.Exit(ExitInfrastructure)
+	}
+	if *project == "" {
+		fail("Missing -project")
+	}
+	switch *format {
+	case QueryFormatTable, QueryFormatJSON, QueryFormatCSV:
+	default:
+		fail("Invalid -format value '%s': expected table, json or csv", *format)
+	}
+	logOptions, err := parseLogOptions(*logLevel, *logFormat)
+	if err != nil {
+		fail("%v", err)
+	}
+	// Logs go to stderr, stdout only carries the query result
+	logger.InitWriter(os.Stderr, logOptions)
+
+	filter := testcase.IncidentFilter{RuleSet: *ruleSet, Category: *category, Label: *label, File: *file, Message: *message}
+	if *rule != "" {
+		if filter.Rule, err = regexp.Compile(*rule); err != nil {
+			fail("Invalid -rule value: %v", err)
+		}
+	}
+	if filter.MinEffort, filter.MaxEffort, err = testcase.ParseEffortRange(*effort); err != nil {
+		fail("Invalid -effort value: %v", err)
+	}
+
+	// The incident store of the last analysis, unless another source is set
+	baselineSet := false
+	flags.Visit(func(f *flag.Flag) { baselineSet = baselineSet || f.Name == "baseline" })
+	testCase := testcase.TestCase{Name: *project, OutputFolder: filepath.Join(*outputFolder, *project)}
+	source := testCase.IncidentStoreFile()
+	switch {
+	case *outputDir != "":
+		source = *outputDir
+	case *existingOutputFolder != "":
+		source = filepath.Join(*existingOutputFolder, *project, "appcat_output")
+	case baselineSet:
+		source = filepath.Join(*baselineFolder, *project, "appcat_output")
+	default:
+		if _, err := os.Stat(source); err != nil {
+			source = filepath.Join(*baselineFolder, *project, "appcat_output")
+		}
+	}
+	if info, err := os.Stat(source); err == nil && info.IsDir() {
+		if _, err := os.Stat(filepath.Join(source, testcase.IncidentsFileName)); err == nil {
+			source = filepath.Join(source, testcase.IncidentsFileName)
+		}
+	}
+
+	var store *testcase.IncidentStore
+	if filepath.Base(source) == testcase.IncidentsFileName {
+		if store, err = testcase.LoadIncidentStore(source); err != nil {
+			fail("Error loading incident store: %v", err)
+		}
+	} else {
+		incidents, _, _, err := testCase.ParseAppCatOutput(source, "")
+		if err != nil {
+			fail("Error parsing AppCat output: %v", err)
+		}
+		store = testCase.BuildIncidentStore(incidents)
+	}
+	found := store.Find(filter)
+
+	switch *format {
+	case QueryFormatJSON:
+		err = writeQueryJSON(os.Stdout, found)
+	case QueryFormatCSV:
+		err = writeQueryCSV(os.Stdout, found)
+	default:
+		err = writeQueryTable(os.Stdout, found)
+		if err == nil {
+			fmt.Printf("\n%d of %d incidents in: %s\n", len(found), store.Len(), source)
+		}
+	}
+	if err != nil {
+		fail("Failed to write query result: %v", err)
+	}
+}
+
+// queryLocation formats the file and line of an incident.
+func queryLocation(incident testcase.StoredIncident) string {
+	return fmt.Sprintf("%s:%d", incident.File, incident.LineNumber)
+}
+
+func writeQueryTable(w io.Writer, incidents []testcase.StoredIncident) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tRULESET\tRULE\tCATEGORY\tEFFORT\tLOCATION\tMESSAGE")
+	for _, incident := range incidents {
+		// The first line of the message, shortened to keep one incident per row
+		text, _, _ := strings.Cut(strings.TrimSpace(incident.Message), "\n")
+		if runes := []rune(text); len(runes) > queryMessageWidth {
+			text = string(runes[:queryMessageWidth-3]) + "..."
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", incident.ID, incident.RuleSet, incident.Rule, incident.Category, incident.Effort, queryLocation(incident), text)
+	}
+	return table.Flush()
+}
+
+func writeQueryJSON(w io.Writer, incidents []testcase.StoredIncident) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(incidents)
+}
+
+func writeQueryCSV(w io.Writer, incidents []testcase.StoredIncident) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"ID", "Key", "RuleSet", "Rule", "Category", "Effort", "File", "Line", "Labels", "Message"})
+	for _, incident := range incidents {
+		writer.Write([]string{
+			incident.ID,
+			incident.Key,
+			incident.RuleSet,
+			incident.Rule,
+			incident.Category,
+			strconv.Itoa(incident.Effort),
+			incident.File,
+			strconv.Itoa(incident.LineNumber),
+			strings.Join(incident.Labels, ";"),
+			incident.Message,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,272 @@
+package testcase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"lianwMS/appcat_validation/logger"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AIReviewScope selects which incidents the ai action sends to the judge.
+type AIReviewScope string
+
+const (
+	AIReviewDiff AIReviewScope = "diff"
+	AIReviewAll  AIReviewScope = "all"
+)
+
+// AIVerdict is the judge's opinion on a single incident.
+// Error is set instead of Result when the judge could not review the incident.
+// PromptVersion is the PromptTemplate ID the incident was reviewed with.
+type AIVerdict struct {
+	Key           string                `yaml:"key" json:"key"`
+	Judge         string                `yaml:"judge" json:"judge"`
+	PromptVersion string                `yaml:"promptVersion" json:"promptVersion"`
+	Verdict       Verdict               `yaml:"verdict,omitempty" json:"verdict,omitempty"`
+	Reason        string                `yaml:"reason,omitempty" json:"reason,omitempty"`
+	Category      FalsePositiveCategory `yaml:"category,omitempty" json:"category,omitempty"`
+	Confidence    float64               `yaml:"confidence,omitempty" json:"confidence,omitempty"`
+	Error         string                `yaml:"error,omitempty" json:"error,omitempty"`
+}
+
+// IsTruePositive reports whether the judge confirmed the incident.
+func (v AIVerdict) IsTruePositive() bool {
+	return v.Verdict == VerdictTruePositive
+}
+
+// IsFalsePositive reports whether the judge rejected the incident.
+func (v AIVerdict) IsFalsePositive() bool {
+	return v.Verdict == VerdictFalsePositive
+}
+
+// Failed reports whether the judge could not review the incident.
+func (v AIVerdict) Failed() bool {
+	return v.Error != ""
+}
+
+// Summary returns a single line description of the verdict for reports.
+func (v AIVerdict) Summary() string {
+	if v.Failed() {
+		return fmt.Sprintf("[AI] %s: review failed: %s", v.Key, v.Error)
+	}
+	if v.IsTruePositive() {
+		return fmt.Sprintf("[AI] %s", v.Key)
+	}
+	return fmt.Sprintf("[AI] %s: %s (%s, %s, confidence %.2f)", v.Key, v.Reason, v.Verdict, v.Category, v.Confidence)
+}
+
+// RunAIReview asks the judge to review the given incidents with up to AIParallelism concurrent
+// calls, and writes the verdicts, ordered by key, to ai_review.yaml in the analyze output folder.
+// Judge errors are recorded per incident instead of aborting the review. Completed verdicts are
+// appended to a progress file so an interrupted review resumes where it left off.
+func (tc *TestCase) RunAIReview(incidents map[string]ValidateIncident) ([]AIVerdict, error) {
+	logger := tc.getLogger(ActionAIReview)
+	if tc.Prompt == nil {
+		prompt, err := DefaultPromptTemplate()
+		if err != nil {
+			return nil, err
+		}
+		tc.Prompt = prompt
+	}
+	logger.Printf("[AIReview] Reviewing %d incidents for project: %s with judge %s and prompt %s", len(incidents), tc.Name, tc.Judge.Name(), tc.promptVersion())
+
+	if err := os.MkdirAll(tc.getAnalysisOutputFolder(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create analyze output folder: %w", err)
+	}
+
+	progress, err := loadAIReviewProgress(tc.getAIReviewProgressFile())
+	if err != nil {
+		return nil, err
+	}
+	if len(progress) > 0 {
+		logger.Printf("[AIReview] Resuming review with %d verdicts from: %s", len(progress), tc.getAIReviewProgressFile())
+	}
+	progressFile, err := os.OpenFile(tc.getAIReviewProgressFile(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open AI review progress file: %w", err)
+	}
+	var progressMutex sync.Mutex
+
+	keys := slices.Sorted(maps.Keys(incidents))
+	verdicts := make([]AIVerdict, len(keys))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range max(tc.AIParallelism, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				verdicts[index] = tc.reviewIncident(keys[index], incidents[keys[index]], progress, func(entry aiReviewProgress) {
+					progressMutex.Lock()
+					defer progressMutex.Unlock()
+					if data, err := json.Marshal(entry); err == nil {
+						progressFile.Write(append(data, '\n'))
+					}
+				})
+			}
+		}()
+	}
+	for index := range keys {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+	progressFile.Close()
+
+	failed := 0
+	for _, verdict := range verdicts {
+		if verdict.Failed() {
+			failed++
+		}
+	}
+	if tc.VerdictCache != nil {
+		hits, misses := tc.VerdictCache.Stats()
+		logger.Printf("[AIReview] Verdict cache: %d hits, %d misses so far", hits, misses)
+	}
+	if failed == 0 {
+		os.Remove(tc.getAIReviewProgressFile())
+	} else {
+		logger.Warnf("[AIReview] %d incidents could not be reviewed, rerun to retry them", failed)
+	}
+
+	data, err := yaml.Marshal(verdicts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal verdicts: %w", err)
+	}
+	if err := os.WriteFile(tc.getAIReviewFile(), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write AI review file: %w", err)
+	}
+	logger.Printf("[AIReview] %d verdicts written to: %s\n", len(verdicts), tc.getAIReviewFile())
+	return verdicts, nil
+}
+
+// aiReviewProgress is one line of the AI review progress file.
+type aiReviewProgress struct {
+	Hash    string    `json:"hash"`
+	Verdict AIVerdict `json:"verdict"`
+}
+
+// loadAIReviewProgress reads the verdicts completed by a previous, interrupted review.
+func loadAIReviewProgress(progressFile string) (map[string]AIVerdict, error) {
+	progress := make(map[string]AIVerdict)
+	data, err := os.ReadFile(progressFile)
+	if os.IsNotExist(err) {
+		return progress, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AI review progress file: %w", err)
+	}
+	for _, line := range strings.Split(string(data), lineDelimiter) {
+		var entry aiReviewProgress
+		// A line truncated by an interruption is skipped, the incident is simply reviewed again
+		if json.Unmarshal([]byte(line), &entry) == nil && entry.Hash != "" {
+			progress[entry.Hash] = entry.Verdict
+		}
+	}
+	return progress, nil
+}
+
+// promptVersion identifies the prompt sent to the judge: the template ID, and the number of
+// source context lines when they are included.
+func (tc *TestCase) promptVersion() string {
+	if tc.PromptContextLines > 0 {
+		return fmt.Sprintf("%s-ctx%d", tc.Prompt.ID(), tc.PromptContextLines)
+	}
+	return tc.Prompt.ID()
+}
+
+// reviewIncident returns the verdict for one incident from the progress of a previous review,
+// the verdict cache or the judge, in that order. New verdicts are passed to record.
+func (tc *TestCase) reviewIncident(key string, incident ValidateIncident, progress map[string]AIVerdict, record func(aiReviewProgress)) AIVerdict {
+	logger := tc.getLogger(ActionAIReview)
+	promptVersion := tc.promptVersion()
+	hash := tc.verdictHash(incident, promptVersion, tc.Judge.Name())
+	if verdict, exists := progress[hash]; exists {
+		verdict.Key = key
+		return verdict
+	}
+	if tc.VerdictCache != nil {
+		if verdict, exists := tc.VerdictCache.Get(hash); exists {
+			logger.Debugf("[AIReview] Cached verdict for incident: %s\n", key)
+			verdict.Key = key
+			record(aiReviewProgress{Hash: hash, Verdict: verdict})
+			return verdict
+		}
+	}
+
+	verdict := AIVerdict{Key: key, Judge: tc.Judge.Name(), PromptVersion: promptVersion}
+	promptData, err := tc.promptData(key, incident, tc.PromptContextLines)
+	if err != nil {
+		verdict.Error = err.Error()
+		return verdict
+	}
+	userPrompt, err := tc.Prompt.Render(promptData)
+	if err != nil {
+		verdict.Error = err.Error()
+		return verdict
+	}
+	logger.Debugf("[AIReview] Reviewing incident: %s\n", key)
+
+	result, err := ValidateSingleIncidentAI(tc.Prompt.System, userPrompt, tc.Judge, logger)
+	if err != nil {
+		logger.Warnf("[AIReview] Failed to review incident %s: %v", key, err)
+		verdict.Error = err.Error()
+		return verdict
+	}
+	verdict.Verdict = result.Verdict
+	verdict.Reason = result.Reason
+	verdict.Category = result.Category
+	verdict.Confidence = result.Confidence
+	record(aiReviewProgress{Hash: hash, Verdict: verdict})
+	if tc.VerdictCache != nil {
+		if err := tc.VerdictCache.Put(hash, verdict); err != nil {
+			logger.Warnf("[AIReview] Failed to cache verdict for incident %s: %v", key, err)
+		}
+	}
+	return verdict
+}
+
+// ValidateSingleIncidentAI asks the judge for a structured verdict on one incident. An answer
+// that does not match VerdictSchema is sent back once with the parse error for repair.
+func ValidateSingleIncidentAI(systemPrompt string, userPrompt_incident string, judge IncidentJudge, logger *logger.Logger) (JudgeVerdict, error) {
+	messages := []JudgeMessage{
+		{Role: RoleSystem, Content: systemPrompt},
+		{Role: RoleUser, Content: userPrompt_incident},
+	}
+
+	for attempt := 0; ; attempt++ {
+		content, err := judge.Complete(context.TODO(), messages, VerdictSchema)
+		if err != nil {
+			return JudgeVerdict{}, fmt.Errorf("judge %s failed: %w", judge.Name(), err)
+		}
+
+		verdict, err := ParseJudgeVerdict(content)
+		if err == nil {
+			logger.Debugf("Validation result: %+v\n", verdict)
+			return verdict, nil
+		}
+		if attempt >= maxVerdictRepairs {
+			return JudgeVerdict{}, fmt.Errorf("invalid verdict after %d repair attempts: %w", maxVerdictRepairs, err)
+		}
+
+		logger.Warnf("Invalid verdict, asking for repair: %v\n", err)
+		messages = append(messages,
+			JudgeMessage{Role: RoleAssistant, Content: content},
+			JudgeMessage{Role: RoleUser, Content: fmt.Sprintf(RepairPrompt, err)},
+		)
+	}
+}
+
+// maxVerdictRepairs is the number of times an invalid answer is sent back for repair.
+const maxVerdictRepairs = 1
+
+// RepairPrompt asks the judge to fix an answer that failed ParseJudgeVerdict.
+const RepairPrompt = `Your previous answer could not be parsed: %v
+Respond again with only the JSON object described in [Result Sample], without any other text.`
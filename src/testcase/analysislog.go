@@ -0,0 +1,207 @@
+package testcase
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	AnalysisLogFileName = "analysis.log"
+)
+
+// logSignature is a known AppCat provider event or failure, matched on the msg and line fields.
+type logSignature struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// logSignatures are the provider events and failures counted in LogSummary.Signatures.
+var logSignatures = []logSignature{
+	{"language_server_restart", regexp.MustCompile(`(?i)retrying language server start`)},
+	{"language_server_crash", regexp.MustCompile(`(?i)language server (crashed|exited|died|stopped unexpectedly)|failed to start language server|(connection|pipe) to (the )?language server`)},
+	{"dependency_resolution_failure", regexp.MustCompile(`(?i)(unable|failed) to (get|resolve|download) dependenc|could not resolve dependencies|unable to open the pom file`)},
+	{"source_download_wait", regexp.MustCompile(`(?i)waiting for source downloads`)},
+	{"code_location_unavailable", regexp.MustCompile(`(?i)unable to get code location`)},
+}
+
+// LogSummary summarizes an AppCat analysis.log: record counts by level, error and warning counts
+// by message and provider, and counts of known provider events and failures.
+type LogSummary struct {
+	Lines      int            `yaml:"lines"`
+	Errors     int            `yaml:"errors"`
+	Warnings   int            `yaml:"warnings"`
+	Messages   map[string]int `yaml:"messages"`
+	Providers  map[string]int `yaml:"providers"`
+	Signatures map[string]int `yaml:"signatures"`
+}
+
+// parseLogfmt parses a logfmt line (key=value key="quoted \"value\"") into its fields.
+func parseLogfmt(line string) map[string]string {
+	fields := make(map[string]string)
+	for index := 0; index < len(line); {
+		for index < len(line) && line[index] == ' ' {
+			index++
+		}
+		start := index
+		for index < len(line) && line[index] != '=' && line[index] != ' ' {
+			index++
+		}
+		key := line[start:index]
+		if index >= len(line) || line[index] != '=' {
+			if key != "" {
+				fields[key] = ""
+			}
+			continue
+		}
+		index++
+
+		var value strings.Builder
+		if index < len(line) && line[index] == '"' {
+			for index++; index < len(line) && line[index] != '"'; index++ {
+				if line[index] == '\\' && index+1 < len(line) {
+					index++
+					switch line[index] {
+					case 'n':
+						value.WriteByte('\n')
+					case 'r':
+						value.WriteByte('\r')
+					case 't':
+						value.WriteByte('\t')
+					default:
+						value.WriteByte(line[index])
+					}
+					continue
+				}
+				value.WriteByte(line[index])
+			}
+			index++
+		} else {
+			for index < len(line) && line[index] != ' ' {
+				value.WriteByte(line[index])
+				index++
+			}
+		}
+		if key != "" {
+			fields[key] = value.String()
+		}
+	}
+	return fields
+}
+
+// ParseAnalysisLog reads an AppCat analysis.log in logfmt format.
+// Errors and warnings are counted by message, with the provider when set, e.g. "unable to start [java]".
+func ParseAnalysisLog(logFile string) (*LogSummary, error) {
+	file, err := os.Open(logFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open analysis log: %w", err)
+	}
+	defer file.Close()
+
+	summary := &LogSummary{Messages: make(map[string]int), Providers: make(map[string]int), Signatures: make(map[string]int)}
+	scanner := bufio.NewScanner(file)
+	// Language server lines embed search results and can be very long
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		summary.Lines++
+		fields := parseLogfmt(scanner.Text())
+		level := fields["level"]
+		if level == "error" || level == "fatal" || level == "panic" || level == "warning" || level == "warn" {
+			if level == "warning" || level == "warn" {
+				summary.Warnings++
+			} else {
+				summary.Errors++
+			}
+			message := fmt.Sprintf("%s: %s", level, fields["msg"])
+			if provider := fields["provider"]; provider != "" {
+				message += fmt.Sprintf(" [%s]", provider)
+				summary.Providers[provider]++
+			}
+			summary.Messages[message]++
+		}
+		for _, signature := range logSignatures {
+			if signature.Pattern.MatchString(fields["msg"]) || signature.Pattern.MatchString(fields["line"]) {
+				summary.Signatures[signature.Name]++
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read analysis log: %w", err)
+	}
+	return summary, nil
+}
+
+func (tc *TestCase) getLogSummaryFile() string {
+	return filepath.Join(tc.getAnalysisOutputFolder(), fmt.Sprintf("%s%s", "analysis_log_summary", YamlExtension))
+}
+
+// ReadAnalysisLog parses the analysis.log in outputFolder, nil when there is none.
+func ReadAnalysisLog(outputFolder string) (*LogSummary, error) {
+	logFile := filepath.Join(outputFolder, AnalysisLogFileName)
+	if _, err := os.Stat(logFile); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return ParseAnalysisLog(logFile)
+}
+
+// RunLogAnalysis writes the summary of the AppCat analysis.log, parsed when the run was
+// classified, to analysis_log_summary.yaml.
+func (tc *TestCase) RunLogAnalysis(summary *LogSummary) error {
+	logger := tc.getLogger("")
+	logger.Printf("[AnalysisLog] %d lines, %d errors, %d warnings, signatures: %v", summary.Lines, summary.Errors, summary.Warnings, summary.Signatures)
+	if err := os.MkdirAll(tc.getAnalysisOutputFolder(), 0755); err != nil {
+		return fmt.Errorf("failed to create analyze output folder: %w", err)
+	}
+	data, err := yaml.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed to marshal analysis log summary: %w", err)
+	}
+	if err := os.WriteFile(tc.getLogSummaryFile(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write analysis log summary: %w", err)
+	}
+	return nil
+}
+
+// LogHealthDetails lists the error, warning and signature counts of summary, compared with the
+// baseline when available. Counts above the baseline are marked as failed and reported as a
+// regression.
+func LogHealthDetails(summary *LogSummary, baseline *LogSummary) (string, bool) {
+	details, regressed := "", false
+	line := func(name string, count int, baselineCount func(*LogSummary) int) {
+		sign, text := signs.PASS, fmt.Sprintf("%s: %d", name, count)
+		if baseline != nil {
+			expected := baselineCount(baseline)
+			text += fmt.Sprintf(" (baseline %d)", expected)
+			if count > expected {
+				sign = signs.FAIL
+				regressed = true
+			}
+		}
+		details += fmt.Sprintf(ItemResultFormatSUBITEM, sign, text) + lineDelimiter
+	}
+	line("errors", summary.Errors, func(s *LogSummary) int { return s.Errors })
+	line("warnings", summary.Warnings, func(s *LogSummary) int { return s.Warnings })
+
+	names := slices.Collect(maps.Keys(summary.Signatures))
+	if baseline != nil {
+		names = append(names, slices.Collect(maps.Keys(baseline.Signatures))...)
+	}
+	slices.Sort(names)
+	for _, name := range slices.Compact(names) {
+		line(name, summary.Signatures[name], func(s *LogSummary) int { return s.Signatures[name] })
+	}
+	for _, message := range slices.Sorted(maps.Keys(summary.Messages)) {
+		line(message, summary.Messages[message], func(s *LogSummary) int { return s.Messages[message] })
+	}
+	return details, regressed
+}
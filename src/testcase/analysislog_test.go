@@ -0,0 +1,95 @@
+package testcase
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseLogfmt(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want map[string]string
+	}{
+		{"plain values", `level=info provider=java`, map[string]string{"level": "info", "provider": "java"}},
+		{"quoted value", `time="2025-06-12T13:24:55+08:00" msg="resolving dependency sources"`,
+			map[string]string{"time": "2025-06-12T13:24:55+08:00", "msg": "resolving dependency sources"}},
+		{"escaped quotes", `msg="unable to open \"pom.xml\"" level=error`, map[string]string{"msg": `unable to open "pom.xml"`, "level": "error"}},
+		{"escaped characters", `line="a\tb\nc\\d\re"`, map[string]string{"line": "a\tb\nc\\d\re"}},
+		{"bare key", `level=error verbose msg=done`, map[string]string{"level": "error", "verbose": "", "msg": "done"}},
+		{"empty value", `error= level=warning`, map[string]string{"error": "", "level": "warning"}},
+		{"empty quoted value", `error="" level=warning`, map[string]string{"error": "", "level": "warning"}},
+		{"unterminated quote", `level=error msg="unable to start language server`, map[string]string{"level": "error", "msg": "unable to start language server"}},
+		{"trailing backslash", `msg="path\`, map[string]string{"msg": `path\`}},
+		{"extra spaces", `  level=info   msg=x  `, map[string]string{"level": "info", "msg": "x"}},
+		{"value with equals", `query=a=b msg="x=y"`, map[string]string{"query": "a=b", "msg": "x=y"}},
+		{"empty key", `=value level=info`, map[string]string{"level": "info"}},
+		{"empty line", ``, map[string]string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseLogfmt(test.line); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseLogfmt(%q) = %q, want %q", test.line, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseAnalysisLog(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), AnalysisLogFileName)
+	lines := `time="2025-06-12T13:24:55+08:00" level=info msg="retrying language server start" provider=java
+time="2025-06-12T13:24:56+08:00" level=error msg="unable to open the pom file" provider=java
+
+time="2025-06-12T13:24:57+08:00" level=error msg="unable to open the pom file" provider=java
+time="2025-06-12T13:24:58+08:00" level=warning msg="waiting for source downloads"
+time="2025-06-12T13:24:59+08:00" level=error msg="failed to evaluate rule" line="unable to get code location"
+`
+	if err := os.WriteFile(logFile, []byte(lines), 0644); err != nil {
+		t.Fatalf("writing log: %v", err)
+	}
+	got, err := ParseAnalysisLog(logFile)
+	if err != nil {
+		t.Fatalf("ParseAnalysisLog: %v", err)
+	}
+	want := &LogSummary{
+		Lines:    5,
+		Errors:   3,
+		Warnings: 1,
+		Messages: map[string]int{
+			"error: unable to open the pom file [java]": 2,
+			"warning: waiting for source downloads":     1,
+			"error: failed to evaluate rule":            1,
+		},
+		Providers: map[string]int{"java": 2},
+		Signatures: map[string]int{
+			"language_server_restart":       1,
+			"dependency_resolution_failure": 2,
+			"source_download_wait":          1,
+			"code_location_unavailable":     1,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAnalysisLog = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseAnalysisLogBaseline(t *testing.T) {
+	summary, err := ParseAnalysisLog(filepath.Join("..", "..", "data", "baseline", "airsonic-advanced", "appcat_output", AnalysisLogFileName))
+	if err != nil {
+		t.Fatalf("ParseAnalysisLog: %v", err)
+	}
+	if summary.Lines != 4768 || summary.Errors != 584 || summary.Warnings != 0 {
+		t.Errorf("ParseAnalysisLog counted %d lines, %d errors, %d warnings, want 4768, 584, 0", summary.Lines, summary.Errors, summary.Warnings)
+	}
+	want := map[string]int{
+		"code_location_unavailable":     584,
+		"dependency_resolution_failure": 3,
+		"language_server_restart":       1,
+		"source_download_wait":          253,
+	}
+	if !reflect.DeepEqual(summary.Signatures, want) {
+		t.Errorf("ParseAnalysisLog signatures = %v, want %v", summary.Signatures, want)
+	}
+}
@@ -0,0 +1,89 @@
+package testcase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VerdictCache is a content-addressed on-disk cache of AI verdicts. Each verdict is stored as
+// <hash>.yaml where the hash covers the normalized incident, the prompt version and the judge model,
+// so a verdict is reused only when exactly the same question is asked to the same model.
+type VerdictCache struct {
+	folder string
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewVerdictCache opens the cache in folder, creating the folder if needed.
+func NewVerdictCache(folder string) (*VerdictCache, error) {
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create verdict cache folder: %w", err)
+	}
+	return &VerdictCache{folder: folder}, nil
+}
+
+// verdictHash hashes the normalized incident together with the prompt version and the judge model.
+// The uri is made relative to the project so caches can be shared between machines.
+func (tc *TestCase) verdictHash(incident ValidateIncident, promptVersion string, model string) string {
+	variables, _ := yaml.Marshal(incident.Variables)
+	normalize := func(text string) string {
+		return strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	}
+	fields := []string{
+		promptVersion,
+		model,
+		incident.RuleSet,
+		incident.Rule,
+		tc.relativeUri(incident.Uri),
+		strconv.Itoa(incident.LineNumber),
+		normalize(incident.Message),
+		normalize(incident.CodeSnip),
+		normalize(string(variables)),
+	}
+	hash := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(hash[:])
+}
+
+func (c *VerdictCache) getFile(key string) string {
+	return filepath.Join(c.folder, fmt.Sprintf("%s%s", key, YamlExtension))
+}
+
+// Get returns the cached verdict for the verdict hash key and records a hit or a miss.
+func (c *VerdictCache) Get(key string) (AIVerdict, bool) {
+	var verdict AIVerdict
+	data, err := os.ReadFile(c.getFile(key))
+	if err == nil {
+		err = yaml.Unmarshal(data, &verdict)
+	}
+	if err != nil {
+		c.misses.Add(1)
+		return AIVerdict{}, false
+	}
+	c.hits.Add(1)
+	return verdict, true
+}
+
+// Put stores the verdict for key.
+func (c *VerdictCache) Put(key string, verdict AIVerdict) error {
+	data, err := yaml.Marshal(verdict)
+	if err != nil {
+		return fmt.Errorf("failed to marshal verdict: %w", err)
+	}
+	if err := os.WriteFile(c.getFile(key), data, 0644); err != nil {
+		return fmt.Errorf("failed to write verdict cache file: %w", err)
+	}
+	return nil
+}
+
+// Stats returns the number of cache hits and misses so far.
+func (c *VerdictCache) Stats() (int64, int64) {
+	return c.hits.Load(), c.misses.Load()
+}
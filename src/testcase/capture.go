@@ -0,0 +1,74 @@
+package testcase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// AppCatError is returned by RunAppCat when AppCat fails, with the last lines it wrote to stderr.
+type AppCatError struct {
+	Err    error
+	Stderr []string
+}
+
+func (e *AppCatError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *AppCatError) Unwrap() error {
+	return e.Err
+}
+
+// FailureMessage formats the report message of a test case that failed with err. When AppCat
+// failed, the tail of its stderr is included as details.
+func FailureMessage(name string, err error) string {
+	message := fmt.Sprintf("Error: %v", err)
+	var appcatErr *AppCatError
+	if errors.As(err, &appcatErr) && len(appcatErr.Stderr) > 0 {
+		stderr := "```" + lineDelimiter + strings.Join(appcatErr.Stderr, lineDelimiter) + lineDelimiter + "```"
+		message += lineDelimiter + fmt.Sprintf(ItemResultFormatDETAILS, stderr)
+	}
+	return fmt.Sprintf(ItemResultFormatFAIL, name, message)
+}
+
+// tailWriter keeps the last max lines written to it, none when max is not positive.
+type tailWriter struct {
+	mutex   sync.Mutex
+	max     int
+	lines   []string
+	partial string
+}
+
+func newTailWriter(lines int) *tailWriter {
+	return &tailWriter{max: max(lines, 0)}
+}
+
+func (w *tailWriter) Write(data []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	text := strings.ReplaceAll(w.partial+string(data), "\r\n", lineDelimiter)
+	lines := strings.Split(text, lineDelimiter)
+	// The last element is an incomplete line, or empty after a line delimiter
+	w.partial = lines[len(lines)-1]
+	w.lines = append(w.lines, lines[:len(lines)-1]...)
+	if len(w.lines) > w.max {
+		w.lines = w.lines[len(w.lines)-w.max:]
+	}
+	return len(data), nil
+}
+
+// Lines returns the last lines written, including an unterminated last line.
+func (w *tailWriter) Lines() []string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	lines := append([]string{}, w.lines...)
+	if w.partial != "" {
+		lines = append(lines, w.partial)
+	}
+	if len(lines) > w.max {
+		lines = lines[len(lines)-w.max:]
+	}
+	return lines
+}
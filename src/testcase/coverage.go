@@ -0,0 +1,232 @@
+package testcase
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// RuleOutcome is how a rule ended in the AppCat output of a project.
+type RuleOutcome string
+
+const (
+	OutcomeViolation RuleOutcome = "violation"
+	OutcomeInsight   RuleOutcome = "insight"
+	OutcomeUnmatched RuleOutcome = "unmatched"
+	OutcomeSkipped   RuleOutcome = "skipped"
+	OutcomeError     RuleOutcome = "error"
+)
+
+// ruleOutcomes lists the outcomes in report order.
+var ruleOutcomes = []RuleOutcome{OutcomeViolation, OutcomeInsight, OutcomeUnmatched, OutcomeSkipped, OutcomeError}
+
+// outcomePrecedence ranks the outcomes of a rule listed more than once, highest first: an error is
+// never hidden by another outcome.
+var outcomePrecedence = []RuleOutcome{OutcomeError, OutcomeViolation, OutcomeInsight, OutcomeUnmatched, OutcomeSkipped}
+
+// RuleRef identifies a rule, rule IDs are only unique within a ruleset.
+type RuleRef struct {
+	RuleSet string
+	Rule    string
+}
+
+// RuleResult is the outcome of a rule in one project, with its incident count for violations and
+// insights and its message for errors.
+type RuleResult struct {
+	Outcome   RuleOutcome
+	Incidents int
+	Error     string
+}
+
+// ProjectCoverage is the outcome of every rule listed in the AppCat output of a project.
+type ProjectCoverage map[RuleRef]RuleResult
+
+// Add records the outcome of the rules of ruleSet. A rule listed more than once keeps the outcome
+// ranked highest by outcomePrecedence, with the incidents of the violation or insight.
+func (c ProjectCoverage) Add(ruleSet *RuleSet) {
+	add := func(rule string, result RuleResult) {
+		ref := RuleRef{RuleSet: ruleSet.Name, Rule: rule}
+		existing, exists := c[ref]
+		if exists && slices.Index(outcomePrecedence, existing.Outcome) < slices.Index(outcomePrecedence, result.Outcome) {
+			existing.Incidents = max(existing.Incidents, result.Incidents)
+			c[ref] = existing
+			return
+		}
+		result.Incidents = max(existing.Incidents, result.Incidents)
+		c[ref] = result
+	}
+	for rule, violation := range ruleSet.Violations {
+		add(rule, RuleResult{Outcome: OutcomeViolation, Incidents: len(violation.Incidents)})
+	}
+	for rule, insight := range ruleSet.Insights {
+		add(rule, RuleResult{Outcome: OutcomeInsight, Incidents: len(insight.Incidents)})
+	}
+	for _, rule := range ruleSet.Unmatched {
+		add(rule, RuleResult{Outcome: OutcomeUnmatched})
+	}
+	for _, rule := range ruleSet.Skipped {
+		add(rule, RuleResult{Outcome: OutcomeSkipped})
+	}
+	for rule, message := range ruleSet.Errors {
+		add(rule, RuleResult{Outcome: OutcomeError, Error: message})
+	}
+}
+
+// RuleCoverage aggregates the outcomes of a rule across the projects of a run.
+type RuleCoverage struct {
+	RuleRef
+	// Projects is the outcome by project, for the projects whose output lists the rule.
+	Projects map[string]RuleResult
+	// Outcomes is the number of projects by outcome.
+	Outcomes map[RuleOutcome]int
+	// Incidents is the number of violation and insight incidents across the projects.
+	Incidents int
+}
+
+// Fired reports whether the rule was a violation or an insight in at least one project.
+func (c RuleCoverage) Fired() bool {
+	return c.Outcomes[OutcomeViolation]+c.Outcomes[OutcomeInsight] > 0
+}
+
+// Always reports whether the rule had the outcome in every project whose output lists it.
+func (c RuleCoverage) Always(outcome RuleOutcome) bool {
+	return len(c.Projects) > 0 && c.Outcomes[outcome] == len(c.Projects)
+}
+
+// BuildCoverageMatrix aggregates the coverage of each project by rule, sorted by ruleset and rule.
+func BuildCoverageMatrix(coverage map[string]ProjectCoverage) []RuleCoverage {
+	rules := make(map[RuleRef]*RuleCoverage)
+	for project, projectCoverage := range coverage {
+		for ref, result := range projectCoverage {
+			rule, exists := rules[ref]
+			if !exists {
+				rule = &RuleCoverage{RuleRef: ref, Projects: make(map[string]RuleResult), Outcomes: make(map[RuleOutcome]int)}
+				rules[ref] = rule
+			}
+			rule.Projects[project] = result
+			rule.Outcomes[result.Outcome]++
+			rule.Incidents += result.Incidents
+		}
+	}
+	matrix := []RuleCoverage{}
+	for _, rule := range rules {
+		matrix = append(matrix, *rule)
+	}
+	slices.SortFunc(matrix, func(a, b RuleCoverage) int {
+		if a.RuleSet != b.RuleSet {
+			return strings.Compare(a.RuleSet, b.RuleSet)
+		}
+		return strings.Compare(a.Rule, b.Rule)
+	})
+	return matrix
+}
+
+// BuildCoverageReport builds the Markdown coverage report of the matrix over projects: outcome
+// counts by ruleset, the rules that never fired and the rules that failed in every project.
+// Rules skipped in every project were not selected by the targets and are only counted.
+// Projects without coverage, with no output or an output that could not be read, are listed
+// with the reason.
+func BuildCoverageReport(matrix []RuleCoverage, projects []string, missing map[string]string) string {
+	var report strings.Builder
+	report.WriteString("# AppCat Rule Coverage\n\n")
+	fired, alwaysSkipped, neverFired, alwaysError := 0, 0, []RuleCoverage{}, []RuleCoverage{}
+	for _, rule := range matrix {
+		switch {
+		case rule.Fired():
+			fired++
+		case rule.Always(OutcomeSkipped):
+			alwaysSkipped++
+		default:
+			neverFired = append(neverFired, rule)
+		}
+		if rule.Always(OutcomeError) {
+			alwaysError = append(alwaysError, rule)
+		}
+	}
+	report.WriteString(fmt.Sprintf("%d rules in %d projects: %d fired, %d never fired, %d skipped in every project, %d failed in every project\n\n",
+		len(matrix), len(projects), fired, len(neverFired), alwaysSkipped, len(alwaysError)))
+	if len(missing) > 0 {
+		report.WriteString(fmt.Sprintf("%d projects without coverage:\n\n", len(missing)))
+		for _, project := range slices.Sorted(maps.Keys(missing)) {
+			report.WriteString(fmt.Sprintf("- %s: %s\n", project, missing[project]))
+		}
+		report.WriteString("\n")
+	}
+
+	report.WriteString("## Rulesets\n\n")
+	report.WriteString("Rule outcomes summed over projects.\n\n")
+	report.WriteString("| Ruleset | Rules | Fired |")
+	for _, outcome := range ruleOutcomes {
+		report.WriteString(fmt.Sprintf(" %s |", outcome))
+	}
+	report.WriteString("\n|---|---|---" + strings.Repeat("|---", len(ruleOutcomes)) + "|\n")
+	byRuleSet := make(map[string][]RuleCoverage)
+	for _, rule := range matrix {
+		byRuleSet[rule.RuleSet] = append(byRuleSet[rule.RuleSet], rule)
+	}
+	for _, ruleSet := range slices.Sorted(maps.Keys(byRuleSet)) {
+		rules := byRuleSet[ruleSet]
+		rulesFired, outcomes := 0, make(map[RuleOutcome]int)
+		for _, rule := range rules {
+			if rule.Fired() {
+				rulesFired++
+			}
+			for outcome, count := range rule.Outcomes {
+				outcomes[outcome] += count
+			}
+		}
+		report.WriteString(fmt.Sprintf("| %s | %d | %d |", ruleSet, len(rules), rulesFired))
+		for _, outcome := range ruleOutcomes {
+			report.WriteString(fmt.Sprintf(" %d |", outcomes[outcome]))
+		}
+		report.WriteString("\n")
+	}
+
+	report.WriteString("\n## Rules Never Fired\n\n")
+	report.WriteString("Rules evaluated but neither a violation nor an insight in any project, with their outcomes.\n\n")
+	for _, rule := range neverFired {
+		outcomes := []string{}
+		for _, outcome := range ruleOutcomes {
+			if count := rule.Outcomes[outcome]; count > 0 {
+				outcomes = append(outcomes, fmt.Sprintf("%s %d", outcome, count))
+			}
+		}
+		report.WriteString(fmt.Sprintf("- %s %s: %s\n", rule.RuleSet, rule.Rule, strings.Join(outcomes, ", ")))
+	}
+	if len(neverFired) == 0 {
+		report.WriteString("None.\n")
+	}
+
+	report.WriteString("\n## Rules Failing Everywhere\n\n")
+	report.WriteString("Rules with an error in every project whose output lists them.\n\n")
+	for _, rule := range alwaysError {
+		project := slices.Min(slices.Collect(maps.Keys(rule.Projects)))
+		report.WriteString(fmt.Sprintf("- %s %s (%d projects): %s\n", rule.RuleSet, rule.Rule, len(rule.Projects), rule.Projects[project].Error))
+	}
+	if len(alwaysError) == 0 {
+		report.WriteString("None.\n")
+	}
+	return report.String()
+}
+
+// CoverageCSV returns the matrix as CSV rows: the rule, its project count by outcome and its
+// outcome in each of projects, empty when the output of the project does not list the rule.
+func CoverageCSV(matrix []RuleCoverage, projects []string) [][]string {
+	header := []string{"RuleSet", "Rule", "Incidents"}
+	for _, outcome := range ruleOutcomes {
+		header = append(header, string(outcome))
+	}
+	rows := [][]string{append(header, projects...)}
+	for _, rule := range matrix {
+		row := []string{rule.RuleSet, rule.Rule, fmt.Sprint(rule.Incidents)}
+		for _, outcome := range ruleOutcomes {
+			row = append(row, fmt.Sprint(rule.Outcomes[outcome]))
+		}
+		for _, project := range projects {
+			row = append(row, string(rule.Projects[project].Outcome))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
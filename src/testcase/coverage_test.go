@@ -0,0 +1,48 @@
+package testcase
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProjectCoverageAdd(t *testing.T) {
+	coverage := make(ProjectCoverage)
+	coverage.Add(&RuleSet{
+		Name:       "azure/java",
+		Violations: map[string]Violation{"fired": {Incidents: []Incident{{}, {}}}, "failed": {Incidents: []Incident{{}}}},
+		Unmatched:  []string{"unmatched", "fired"},
+		Skipped:    []string{"skipped"},
+		Errors:     map[string]string{"failed": "evaluation failed"},
+	})
+	want := ProjectCoverage{
+		{RuleSet: "azure/java", Rule: "fired"}:     {Outcome: OutcomeViolation, Incidents: 2},
+		{RuleSet: "azure/java", Rule: "failed"}:    {Outcome: OutcomeError, Incidents: 1, Error: "evaluation failed"},
+		{RuleSet: "azure/java", Rule: "unmatched"}: {Outcome: OutcomeUnmatched},
+		{RuleSet: "azure/java", Rule: "skipped"}:   {Outcome: OutcomeSkipped},
+	}
+	if len(coverage) != len(want) {
+		t.Fatalf("coverage has %d rules, want %d: %+v", len(coverage), len(want), coverage)
+	}
+	for ref, result := range want {
+		if coverage[ref] != result {
+			t.Errorf("coverage of %s = %+v, want %+v", ref.Rule, coverage[ref], result)
+		}
+	}
+}
+
+func TestBuildCoverageReportMissingProject(t *testing.T) {
+	coverage := map[string]ProjectCoverage{
+		"alpha": {{RuleSet: "azure/java", Rule: "fired"}: {Outcome: OutcomeViolation, Incidents: 1}},
+	}
+	missing := map[string]string{"beta": "error reading rule coverage for project beta: bad output"}
+	report := BuildCoverageReport(BuildCoverageMatrix(coverage), []string{"alpha"}, missing)
+	if !strings.Contains(report, "1 rules in 1 projects: 1 fired") {
+		t.Errorf("report counts the project without coverage:\n%s", report)
+	}
+	if !strings.Contains(report, "1 projects without coverage:\n\n- beta: error reading rule coverage for project beta: bad output\n") {
+		t.Errorf("report does not list the project without coverage:\n%s", report)
+	}
+	if strings.Contains(report, OutputFileName) {
+		t.Errorf("report lists %s as a rule:\n%s", OutputFileName, report)
+	}
+}
@@ -0,0 +1,246 @@
+package testcase
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LabeledIncident is an incident with its ground-truth verdict, one entry of an evaluation dataset.
+// Project is the project folder name, used to make the uri relative and to read source context.
+// Category is the expected FalsePositiveCategory of false positives.
+type LabeledIncident struct {
+	Key      string                `yaml:"key"`
+	Project  string                `yaml:"project"`
+	Label    Verdict               `yaml:"label"`
+	Category FalsePositiveCategory `yaml:"category"`
+	Incident ValidateIncident      `yaml:"incident"`
+}
+
+// EvalResult is the judge verdict on a labeled incident.
+type EvalResult struct {
+	Key       string                `yaml:"key"`
+	Label     Verdict               `yaml:"label"`
+	Category  FalsePositiveCategory `yaml:"category"`
+	Predicted AIVerdict             `yaml:"predicted"`
+}
+
+// predictedClass returns the predicted verdict, or "error" when the review failed.
+func (r EvalResult) predictedClass() string {
+	if r.Predicted.Failed() {
+		return "error"
+	}
+	return string(r.Predicted.Verdict)
+}
+
+// correct reports whether the judge agreed with the label, including the category of false positives.
+func (r EvalResult) correct() bool {
+	if r.Predicted.Failed() || r.Predicted.Verdict != r.Label {
+		return false
+	}
+	return r.Label != VerdictFalsePositive || r.Predicted.Category == r.Category
+}
+
+// LoadEvalDataset reads a YAML dataset file, or every .yaml file of a folder, each holding a list
+// of LabeledIncident. Entries without a key are keyed "<file>#<index>".
+func LoadEvalDataset(datasetPath string) ([]LabeledIncident, error) {
+	info, err := os.Stat(datasetPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+	files := []string{datasetPath}
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(datasetPath, "*"+YamlExtension))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list dataset files: %w", err)
+		}
+		slices.Sort(files)
+	}
+
+	dataset := []LabeledIncident{}
+	keys := make(map[string]bool)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dataset file: %w", err)
+		}
+		var entries []LabeledIncident
+		if err := yaml.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal dataset file %s: %w", file, err)
+		}
+		for index, entry := range entries {
+			if entry.Key == "" {
+				entry.Key = fmt.Sprintf("%s#%d", filepath.Base(file), index)
+			}
+			if keys[entry.Key] {
+				return nil, fmt.Errorf("duplicate key '%s' in dataset file %s", entry.Key, file)
+			}
+			keys[entry.Key] = true
+			if entry.Label != VerdictTruePositive && entry.Label != VerdictFalsePositive {
+				return nil, fmt.Errorf("label '%s' of %s is not %s or %s", entry.Label, entry.Key, VerdictTruePositive, VerdictFalsePositive)
+			}
+			if entry.Label == VerdictTruePositive {
+				entry.Category = CategoryNone
+			} else if !slices.Contains(categories, entry.Category) || entry.Category == CategoryNone {
+				return nil, fmt.Errorf("category '%s' of false positive %s is not one of %v", entry.Category, entry.Key, categories[1:])
+			}
+			dataset = append(dataset, entry)
+		}
+	}
+	return dataset, nil
+}
+
+// EvaluateJudge reviews every labeled incident with judge and prompt, with up to parallelism
+// concurrent calls. Source context is read from <projectsFolder>/<project> when contextLines is positive.
+// Verdicts are never cached so every evaluation asks the judge.
+func EvaluateJudge(dataset []LabeledIncident, judge IncidentJudge, prompt *PromptTemplate, projectsFolder string, contextLines int, parallelism int) []EvalResult {
+	results := make([]EvalResult, len(dataset))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range max(parallelism, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				entry := dataset[index]
+				tc := TestCase{
+					Name:               entry.Project,
+					ProjectFolder:      filepath.Join(projectsFolder, entry.Project),
+					Judge:              judge,
+					Prompt:             prompt,
+					PromptContextLines: contextLines,
+				}
+				verdict := tc.reviewIncident(entry.Key, entry.Incident, map[string]AIVerdict{}, func(aiReviewProgress) {})
+				results[index] = EvalResult{Key: entry.Key, Label: entry.Label, Category: entry.Category, Predicted: verdict}
+			}
+		}()
+	}
+	for index := range dataset {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// EvalMetrics scores the judge at detecting false positives: a false_positive verdict is a
+// positive prediction, uncertain verdicts and failed reviews are negative predictions.
+type EvalMetrics struct {
+	Total     int
+	Errors    int
+	Precision float64
+	Recall    float64
+	F1        float64
+	Accuracy  float64
+	// Confusion counts label -> predicted verdict, or "error".
+	Confusion map[Verdict]map[string]int
+	// CategoryTotal and CategoryCorrect count incidents and correct verdicts per labeled category.
+	CategoryTotal   map[FalsePositiveCategory]int
+	CategoryCorrect map[FalsePositiveCategory]int
+}
+
+// ComputeEvalMetrics computes precision, recall, the confusion matrix and per-category accuracy.
+func ComputeEvalMetrics(results []EvalResult) EvalMetrics {
+	metrics := EvalMetrics{
+		Total:           len(results),
+		Confusion:       map[Verdict]map[string]int{VerdictTruePositive: {}, VerdictFalsePositive: {}},
+		CategoryTotal:   make(map[FalsePositiveCategory]int),
+		CategoryCorrect: make(map[FalsePositiveCategory]int),
+	}
+	truePositives, falsePositives, falseNegatives, correct := 0, 0, 0, 0
+	for _, result := range results {
+		metrics.Confusion[result.Label][result.predictedClass()]++
+		if result.Predicted.Failed() {
+			metrics.Errors++
+		}
+		flagged := result.Predicted.IsFalsePositive()
+		switch {
+		case flagged && result.Label == VerdictFalsePositive:
+			truePositives++
+		case flagged:
+			falsePositives++
+		case result.Label == VerdictFalsePositive:
+			falseNegatives++
+		}
+		metrics.CategoryTotal[result.Category]++
+		if result.correct() {
+			metrics.CategoryCorrect[result.Category]++
+			correct++
+		}
+	}
+	metrics.Precision = ratio(truePositives, truePositives+falsePositives)
+	metrics.Recall = ratio(truePositives, truePositives+falseNegatives)
+	if metrics.Precision+metrics.Recall > 0 {
+		metrics.F1 = 2 * metrics.Precision * metrics.Recall / (metrics.Precision + metrics.Recall)
+	}
+	metrics.Accuracy = ratio(correct, len(results))
+	return metrics
+}
+
+func ratio(numerator int, denominator int) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
+
+// BuildEvalReport renders the evaluation as Markdown: metrics, confusion matrix, per-category
+// accuracy and the incidents the judge got wrong.
+func BuildEvalReport(results []EvalResult, judgeName string, promptVersion string) string {
+	metrics := ComputeEvalMetrics(results)
+	var report strings.Builder
+	report.WriteString("# AI Judge Evaluation\n\n")
+	report.WriteString(fmt.Sprintf("Judge: %s, prompt: %s, %d incidents, %d reviews failed\n\n", judgeName, promptVersion, metrics.Total, metrics.Errors))
+
+	report.WriteString("## Metrics\n\n")
+	report.WriteString("Positive class: false_positive.\n\n")
+	report.WriteString("| Precision | Recall | F1 | Accuracy |\n|---|---|---|---|\n")
+	report.WriteString(fmt.Sprintf("| %.3f | %.3f | %.3f | %.3f |\n\n", metrics.Precision, metrics.Recall, metrics.F1, metrics.Accuracy))
+
+	predictedClasses := []string{string(VerdictTruePositive), string(VerdictFalsePositive), string(VerdictUncertain), "error"}
+	report.WriteString("## Confusion Matrix\n\n")
+	report.WriteString("| Label \\ Predicted | " + strings.Join(predictedClasses, " | ") + " |\n")
+	report.WriteString("|---" + strings.Repeat("|---", len(predictedClasses)) + "|\n")
+	for _, label := range []Verdict{VerdictTruePositive, VerdictFalsePositive} {
+		report.WriteString(fmt.Sprintf("| %s |", label))
+		for _, predicted := range predictedClasses {
+			report.WriteString(fmt.Sprintf(" %d |", metrics.Confusion[label][predicted]))
+		}
+		report.WriteString("\n")
+	}
+
+	report.WriteString("\n## Per-Category Accuracy\n\n")
+	report.WriteString("| Category | Incidents | Correct | Accuracy |\n|---|---|---|---|\n")
+	for _, category := range slices.Sorted(maps.Keys(metrics.CategoryTotal)) {
+		total, correct := metrics.CategoryTotal[category], metrics.CategoryCorrect[category]
+		report.WriteString(fmt.Sprintf("| %s | %d | %d | %.3f |\n", category, total, correct, ratio(correct, total)))
+	}
+
+	report.WriteString("\n## Mistakes\n\n")
+	mistakes := 0
+	for _, result := range results {
+		if result.correct() {
+			continue
+		}
+		mistakes++
+		predicted := result.predictedClass()
+		if result.Predicted.IsFalsePositive() {
+			predicted += fmt.Sprintf(" (%s)", result.Predicted.Category)
+		}
+		detail := result.Predicted.Reason
+		if result.Predicted.Failed() {
+			detail = result.Predicted.Error
+		}
+		report.WriteString(fmt.Sprintf("- %s: expected %s (%s), got %s: %s\n", result.Key, result.Label, result.Category, predicted, detail))
+	}
+	if mistakes == 0 {
+		report.WriteString("None.\n")
+	}
+	return report.String()
+}
@@ -0,0 +1,247 @@
+package testcase
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Categories only produced by the heuristic analyzer, on top of the AI verdict categories.
+const (
+	CategoryBuildOutput FalsePositiveCategory = "build_output"
+	CategoryTestCode    FalsePositiveCategory = "test_code"
+)
+
+// HeuristicFinding flags an incident as a likely false positive without asking a language model.
+type HeuristicFinding struct {
+	Key      string                `yaml:"key"`
+	Category FalsePositiveCategory `yaml:"category"`
+	Reason   string                `yaml:"reason"`
+}
+
+// Summary returns a single line description of the finding for reports.
+func (f HeuristicFinding) Summary() string {
+	return fmt.Sprintf("[Heuristic] %s: %s (%s)", f.Key, f.Reason, f.Category)
+}
+
+var (
+	documentationExtensions = []string{".md", ".markdown", ".txt", ".adoc", ".asciidoc", ".rst"}
+	documentationFolders    = []string{"doc", "docs", "documentation"}
+	testFolders             = []string{"test", "tests", "it", "testing"}
+	// buildOutputFolders maps build output folders to the source folder they are copied from.
+	buildOutputFolders = map[string]string{
+		"bin/":             "src/",
+		"target/classes/":  "src/main/resources/",
+		"build/resources/": "src/",
+		"out/":             "src/",
+	}
+	snippetLinePattern = regexp.MustCompile(`^\s*(\d+)  (.*)$`)
+)
+
+// incidentPath returns the incident file path relative to the project root, lower-cased with forward slashes.
+func (tc *TestCase) incidentPath(uri string) string {
+	relative := strings.ReplaceAll(tc.relativeUri(uri), "\\", "/")
+	relative = strings.TrimPrefix(relative, tc.Name+"/")
+	return strings.ToLower(relative)
+}
+
+// AnalyzeHeuristics flags incidents matched in documentation, in comments, in build output
+// (such as bin/ copies of src/ resources) and in test code. Findings are ordered by key.
+func (tc *TestCase) AnalyzeHeuristics(incidents map[string]ValidateIncident) []HeuristicFinding {
+	// Index incidents by rule, path and line to find build output duplicates of source files
+	locations := make(map[string]bool)
+	for _, incident := range incidents {
+		locations[fmt.Sprintf("%s|%s|%s|%d", incident.RuleSet, incident.Rule, tc.incidentPath(incident.Uri), incident.LineNumber)] = true
+	}
+
+	findings := []HeuristicFinding{}
+	for _, key := range slices.Sorted(maps.Keys(incidents)) {
+		incident := incidents[key]
+		filePath := tc.incidentPath(incident.Uri)
+		if filePath == "" {
+			continue
+		}
+		folders := strings.Split(path.Dir(filePath), "/")
+		base := path.Base(filePath)
+
+		switch {
+		case strings.HasPrefix(base, "readme") || slices.Contains(documentationExtensions, path.Ext(base)):
+			findings = append(findings, HeuristicFinding{Key: key, Category: CategoryDocumentation,
+				Reason: fmt.Sprintf("match in documentation file %s", base)})
+			continue
+		case containsAny(folders, documentationFolders):
+			findings = append(findings, HeuristicFinding{Key: key, Category: CategoryDocumentation,
+				Reason: fmt.Sprintf("match in documentation folder %s", path.Dir(filePath))})
+			continue
+		}
+
+		if reason, isBuildOutput := buildOutputReason(filePath, func(sourcePath string) bool {
+			return locations[fmt.Sprintf("%s|%s|%s|%d", incident.RuleSet, incident.Rule, sourcePath, incident.LineNumber)]
+		}); isBuildOutput {
+			findings = append(findings, HeuristicFinding{Key: key, Category: CategoryBuildOutput, Reason: reason})
+			continue
+		}
+
+		if isTestPath(folders, base) {
+			findings = append(findings, HeuristicFinding{Key: key, Category: CategoryTestCode,
+				Reason: fmt.Sprintf("match in test code %s", filePath)})
+			continue
+		}
+
+		if line, exists := snippetLine(incident.CodeSnip, incident.LineNumber); exists && isCommentLine(base, incident.CodeSnip, incident.LineNumber, line) {
+			findings = append(findings, HeuristicFinding{Key: key, Category: CategoryComment,
+				Reason: fmt.Sprintf("line %d is a comment: %s", incident.LineNumber, strings.TrimSpace(line))})
+		}
+	}
+	return findings
+}
+
+func containsAny(values []string, candidates []string) bool {
+	for _, value := range values {
+		if slices.Contains(candidates, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTestPath reports whether a file is test code: in a test folder or named like a test class.
+// Files under src/main are production code, even in a package named like a test folder.
+func isTestPath(folders []string, base string) bool {
+	for i := 0; i+1 < len(folders); i++ {
+		if folders[i] == "src" && folders[i+1] == "main" {
+			return false
+		}
+	}
+	return containsAny(folders, testFolders) || strings.HasSuffix(base, "test.java") || strings.HasSuffix(base, "tests.java")
+}
+
+// buildOutputReason reports whether filePath is in a build output folder, and whether the same
+// match exists in the source folder it was copied from.
+func buildOutputReason(filePath string, matchedInSource func(string) bool) (string, bool) {
+	for _, outputFolder := range slices.Sorted(maps.Keys(buildOutputFolders)) {
+		index := strings.Index("/"+filePath, "/"+outputFolder)
+		if index == -1 {
+			continue
+		}
+		sourcePath := filePath[:index] + buildOutputFolders[outputFolder] + filePath[index+len(outputFolder):]
+		if matchedInSource(sourcePath) {
+			return fmt.Sprintf("duplicate of %s in build output folder %s", sourcePath, outputFolder), true
+		}
+		return fmt.Sprintf("match in build output folder %s", outputFolder), true
+	}
+	return "", false
+}
+
+// snippetLines parses a codeSnip ("   25  text" per line) into line numbers and text.
+func snippetLines(codeSnip string) ([]int, []string) {
+	numbers, texts := []int{}, []string{}
+	for _, line := range strings.Split(strings.ReplaceAll(codeSnip, "\r\n", "\n"), "\n") {
+		match := snippetLinePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		number, _ := strconv.Atoi(match[1])
+		numbers = append(numbers, number)
+		texts = append(texts, match[2])
+	}
+	return numbers, texts
+}
+
+// snippetLine returns the text of lineNumber in the codeSnip.
+func snippetLine(codeSnip string, lineNumber int) (string, bool) {
+	numbers, texts := snippetLines(codeSnip)
+	index := slices.Index(numbers, lineNumber)
+	if index == -1 {
+		return "", false
+	}
+	return texts[index], true
+}
+
+// isCommentLine reports whether the line is entirely a comment in the syntax of the file type.
+// Block comments opened earlier in the snippet are taken into account.
+func isCommentLine(base string, codeSnip string, lineNumber int, line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return false
+	}
+	switch path.Ext(base) {
+	case ".java", ".kt", ".groovy", ".scala", ".js", ".ts", ".gradle":
+		return strings.HasPrefix(trimmed, "//") || (strings.HasPrefix(trimmed, "/*") && (!strings.Contains(trimmed, "*/") || strings.HasSuffix(trimmed, "*/"))) ||
+			strings.HasPrefix(trimmed, "*") || insideBlockComment(codeSnip, lineNumber, "/*", "*/")
+	case ".xml", ".html", ".htm", ".xhtml", ".jsp", ".xsd", ".wsdl", ".pom":
+		return (strings.HasPrefix(trimmed, "<!--") && (!strings.Contains(trimmed, "-->") || strings.HasSuffix(trimmed, "-->"))) ||
+			insideBlockComment(codeSnip, lineNumber, "<!--", "-->")
+	case ".properties":
+		return strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "!")
+	case ".yaml", ".yml", ".sh", ".conf", ".cfg", ".toml":
+		return strings.HasPrefix(trimmed, "#")
+	}
+	return false
+}
+
+// insideBlockComment reports whether lineNumber starts inside a block comment opened on an
+// earlier snippet line and is not followed by code once the comment is closed.
+func insideBlockComment(codeSnip string, lineNumber int, open string, close string) bool {
+	numbers, texts := snippetLines(codeSnip)
+	inside := false
+	for i, number := range numbers {
+		if number == lineNumber {
+			return inside && (!strings.Contains(texts[i], close) || strings.HasSuffix(strings.TrimSpace(texts[i]), close))
+		}
+		text := texts[i]
+		for text != "" {
+			if inside {
+				end := strings.Index(text, close)
+				if end == -1 {
+					break
+				}
+				inside = false
+				text = text[end+len(close):]
+			} else {
+				start := strings.Index(text, open)
+				if start == -1 {
+					break
+				}
+				inside = true
+				text = text[start+len(open):]
+			}
+		}
+	}
+	return false
+}
+
+// RunHeuristics reads the incidents of the AppCat output, flags likely false positives and writes the findings
+// to heuristics.yaml in the analyze output folder.
+func (tc *TestCase) RunHeuristics() ([]HeuristicFinding, error) {
+	logger := tc.getLogger(ActionHeuristics)
+	incidents, err := tc.readIncidents()
+	if err != nil {
+		return nil, fmt.Errorf("[Heuristics] Error parsing AppCat output: %w", err)
+	}
+
+	findings := tc.AnalyzeHeuristics(incidents)
+	for _, finding := range findings {
+		logger.Debugf("[Heuristics] %s", finding.Summary())
+	}
+
+	if err := os.MkdirAll(tc.getAnalysisOutputFolder(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create analyze output folder: %w", err)
+	}
+	data, err := yaml.Marshal(findings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal heuristic findings: %w", err)
+	}
+	if err := os.WriteFile(tc.getHeuristicsFile(), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write heuristics file: %w", err)
+	}
+	logger.Printf("[Heuristics] %d of %d incidents flagged for project %s, written to: %s\n", len(findings), len(incidents), tc.Name, tc.getHeuristicsFile())
+	return findings, nil
+}
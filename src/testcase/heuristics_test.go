@@ -0,0 +1,98 @@
+package testcase
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsCommentLine(t *testing.T) {
+	javaSnip := "   10  package app;\n   11  /*\n   12   AWS credentials\n   13  */\n   14  String key = \"aws\"; // AWS\n" +
+		"   15  /* old */ String secret = \"aws\";\n   16  /**\n   17   * AWS credentials\n   18   */\n" +
+		"   19  /* start\n   20   end */ int port = 80;\n   21  // AWS credentials\n"
+	xmlSnip := "    1  <project>\n    2    <!-- AWS\n    3    credentials\n    4    -->\n    5    <aws>key</aws>\n" +
+		"    6    <!-- AWS --> <aws/>\n    7    <!-- AWS -->\n    8    <!--\n    9    --> <aws/>\n"
+	propertiesSnip := "    1  # AWS credentials\n    2  ! AWS credentials\n    3  aws.key=value\n    4  aws.url=http://host/#anchor\n"
+	yamlSnip := "    1  # AWS credentials\n    2  aws:\n    3    key: value # AWS\n    4    # secret: value\n"
+
+	tests := []struct {
+		name     string
+		base     string
+		codeSnip string
+		line     int
+		want     bool
+	}{
+		{"java package", "App.java", javaSnip, 10, false},
+		{"java block comment opening", "App.java", javaSnip, 11, true},
+		{"java inside block comment", "App.java", javaSnip, 12, true},
+		{"java block comment closing", "App.java", javaSnip, 13, true},
+		{"java code with trailing comment", "App.java", javaSnip, 14, false},
+		{"java code after inline block comment", "App.java", javaSnip, 15, false},
+		{"javadoc opening", "App.java", javaSnip, 16, true},
+		{"javadoc line", "App.java", javaSnip, 17, true},
+		{"javadoc closing", "App.java", javaSnip, 18, true},
+		{"java code after block comment closing", "App.java", javaSnip, 20, false},
+		{"java line comment", "App.java", javaSnip, 21, true},
+		{"xml element", "pom.xml", xmlSnip, 1, false},
+		{"xml comment opening", "pom.xml", xmlSnip, 2, true},
+		{"xml inside comment", "pom.xml", xmlSnip, 3, true},
+		{"xml comment closing", "pom.xml", xmlSnip, 4, true},
+		{"xml element after comment", "pom.xml", xmlSnip, 5, false},
+		{"xml element after inline comment", "pom.xml", xmlSnip, 6, false},
+		{"xml single line comment", "pom.xml", xmlSnip, 7, true},
+		{"xml element after comment closing", "pom.xml", xmlSnip, 9, false},
+		{"properties hash comment", "application.properties", propertiesSnip, 1, true},
+		{"properties bang comment", "application.properties", propertiesSnip, 2, true},
+		{"properties value", "application.properties", propertiesSnip, 3, false},
+		{"properties value with hash", "application.properties", propertiesSnip, 4, false},
+		{"yaml comment", "application.yaml", yamlSnip, 1, true},
+		{"yaml key", "application.yaml", yamlSnip, 2, false},
+		{"yaml value with trailing comment", "application.yml", yamlSnip, 3, false},
+		{"yaml indented comment", "application.yml", yamlSnip, 4, true},
+		{"unknown file type", "App.cs", "    1  // AWS\n", 1, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			line, exists := snippetLine(test.codeSnip, test.line)
+			if !exists {
+				t.Fatalf("line %d not found in the snippet", test.line)
+			}
+			if got := isCommentLine(test.base, test.codeSnip, test.line, line); got != test.want {
+				t.Errorf("isCommentLine(%s, %d: %q) = %v, want %v", test.base, test.line, line, got, test.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeHeuristics(t *testing.T) {
+	tc := TestCase{Name: "app"}
+	uri := func(file string) string {
+		return "file:///C:/repos/app/" + file
+	}
+	incident := func(file string, line int, codeSnip string) ValidateIncident {
+		return ValidateIncident{RuleSet: "azure/java", Rule: "aws-01000", Uri: uri(file), LineNumber: line, CodeSnip: codeSnip}
+	}
+	incidents := map[string]ValidateIncident{
+		"bin-copy":        incident("bin/main/resources/application.properties", 3, ""),
+		"bin-only":        incident("bin/main/resources/other.properties", 3, ""),
+		"source":          incident("src/main/resources/application.properties", 3, "    3  aws.key=value\n"),
+		"readme":          incident("README.md", 1, ""),
+		"docs":            incident("docs/setup/aws.html", 1, ""),
+		"test-folder":     incident("src/test/java/app/AwsClient.java", 5, ""),
+		"test-class":      incident("module/AwsClientTest.java", 5, ""),
+		"testing-package": incident("src/main/java/app/testing/AwsClient.java", 5, "    5  client.connect();\n"),
+		"it-package":      incident("src/main/java/app/it/AwsClient.java", 5, "    5  client.connect();\n"),
+		"comment":         incident("src/main/java/app/AwsClient.java", 7, "    6  /*\n    7   AWS client\n    8  */\n"),
+	}
+	want := []HeuristicFinding{
+		{Key: "bin-copy", Category: CategoryBuildOutput, Reason: "duplicate of src/main/resources/application.properties in build output folder bin/"},
+		{Key: "bin-only", Category: CategoryBuildOutput, Reason: "match in build output folder bin/"},
+		{Key: "comment", Category: CategoryComment, Reason: "line 7 is a comment: AWS client"},
+		{Key: "docs", Category: CategoryDocumentation, Reason: "match in documentation folder docs/setup"},
+		{Key: "readme", Category: CategoryDocumentation, Reason: "match in documentation file readme.md"},
+		{Key: "test-class", Category: CategoryTestCode, Reason: "match in test code module/awsclienttest.java"},
+		{Key: "test-folder", Category: CategoryTestCode, Reason: "match in test code src/test/java/app/awsclient.java"},
+	}
+	if got := tc.AnalyzeHeuristics(incidents); !reflect.DeepEqual(got, want) {
+		t.Errorf("AnalyzeHeuristics =\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -0,0 +1,144 @@
+package testcase
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// IncidentsFileName is the incident store of a project in the analyze output folder.
+	IncidentsFileName = "incidents.jsonl"
+)
+
+// StoredIncident is an incident of the incident store. ID is derived from the incident key, so
+// the same incident has the same ID in every run and on every machine. File is the uri relative
+// to the project, as used in the key.
+type StoredIncident struct {
+	ID   string `json:"id"`
+	Key  string `json:"key"`
+	File string `json:"file"`
+	ValidateIncident
+}
+
+// IncidentID returns the stable ID of the incident with key.
+func IncidentID(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:8])
+}
+
+// StoredIncidents returns the incidents by key with their IDs and files, in key order.
+func (tc *TestCase) StoredIncidents(incidents map[string]ValidateIncident) []StoredIncident {
+	stored := make([]StoredIncident, 0, len(incidents))
+	for _, key := range slices.Sorted(maps.Keys(incidents)) {
+		incident := incidents[key]
+		stored = append(stored, StoredIncident{ID: IncidentID(key), Key: key, File: tc.relativeUri(incident.Uri), ValidateIncident: incident})
+	}
+	return stored
+}
+
+// IncidentStoreFile returns the incident store written by the analyze action of the test case.
+func (tc *TestCase) IncidentStoreFile() string {
+	return filepath.Join(tc.getAnalysisOutputFolder(), IncidentsFileName)
+}
+
+// writeIncidentStore writes the incidents to the incident store in folder, one JSON object per
+// line in key order.
+func (tc *TestCase) writeIncidentStore(folder string, incidents map[string]ValidateIncident) error {
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return fmt.Errorf("failed to create incident store folder: %w", err)
+	}
+	file, err := os.Create(filepath.Join(folder, IncidentsFileName))
+	if err != nil {
+		return fmt.Errorf("failed to create incident store: %w", err)
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, stored := range tc.StoredIncidents(incidents) {
+		if err := encoder.Encode(stored); err != nil {
+			return fmt.Errorf("failed to write incident %s: %w", stored.Key, err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write incident store: %w", err)
+	}
+	return nil
+}
+
+// IncidentStore is an incident store loaded in memory, in key order.
+type IncidentStore struct {
+	incidents []StoredIncident
+}
+
+// LoadIncidentStore reads the incident store file.
+func LoadIncidentStore(storeFile string) (*IncidentStore, error) {
+	file, err := os.Open(storeFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open incident store: %w", err)
+	}
+	defer file.Close()
+
+	store := &IncidentStore{}
+	scanner := bufio.NewScanner(file)
+	// Code snippets and variables make long lines
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var incident StoredIncident
+		if err := json.Unmarshal(scanner.Bytes(), &incident); err != nil {
+			return nil, fmt.Errorf("failed to parse incident store %s at line %d: %w", storeFile, line, err)
+		}
+		store.incidents = append(store.incidents, incident)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read incident store: %w", err)
+	}
+	return store, nil
+}
+
+// BuildIncidentStore builds an incident store in memory from the incidents by key, for AppCat
+// outputs the analyze action did not store.
+func (tc *TestCase) BuildIncidentStore(incidents map[string]ValidateIncident) *IncidentStore {
+	return &IncidentStore{incidents: tc.StoredIncidents(incidents)}
+}
+
+// Len returns the number of incidents in the store.
+func (s *IncidentStore) Len() int {
+	return len(s.incidents)
+}
+
+// Incidents returns the incidents of the store by key.
+func (s *IncidentStore) Incidents() map[string]ValidateIncident {
+	incidents := make(map[string]ValidateIncident, len(s.incidents))
+	for _, incident := range s.incidents {
+		incidents[incident.Key] = incident.ValidateIncident
+	}
+	return incidents
+}
+
+// readIncidents returns the incidents of the AppCat output, from the incident store when the
+// analyze step wrote it in this run.
+func (tc *TestCase) readIncidents() (map[string]ValidateIncident, error) {
+	if containsAction(tc.ActionList, ActionAnalyze) {
+		if _, err := os.Stat(tc.IncidentStoreFile()); err == nil {
+			store, err := LoadIncidentStore(tc.IncidentStoreFile())
+			if err != nil {
+				return nil, err
+			}
+			tc.getLogger("").Debugf("[ParseOutput] Read %d incidents from: %s", store.Len(), tc.IncidentStoreFile())
+			return store.Incidents(), nil
+		}
+	}
+	incidents, _, _, err := tc.ParseAppCatOutput(tc.getAppcatOutputFolder(), "")
+	return incidents, err
+}
@@ -0,0 +1,306 @@
+package testcase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// JudgeMessage is one message of a chat conversation with the judge.
+type JudgeMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// IncidentJudge sends a conversation to a language model and returns its raw answer.
+// When responseSchema is set, judges supporting structured output constrain the answer to it.
+type IncidentJudge interface {
+	// Name identifies the provider and model, e.g. "azure/llm-gpt-4o".
+	Name() string
+	Complete(ctx context.Context, messages []JudgeMessage, responseSchema string) (string, error)
+}
+
+type JudgeProvider string
+
+const (
+	JudgeAzureOpenAI JudgeProvider = "azure"
+	JudgeOpenAI      JudgeProvider = "openai"
+	JudgeFake        JudgeProvider = "fake"
+)
+
+// JudgeConfig selects and configures the IncidentJudge used for AI review. It can be loaded from
+// a YAML file with LoadJudgeConfig, the API key is only read from the environment. Timeout bounds
+// each call to the model endpoint, 0 for no limit.
+type JudgeConfig struct {
+	Provider    JudgeProvider `yaml:"provider"`
+	Endpoint    string        `yaml:"endpoint"`
+	Deployment  string        `yaml:"deployment"`
+	ApiKey      string        `yaml:"-"`
+	Temperature float32       `yaml:"temperature"`
+	TopP        float32       `yaml:"topP"`
+	MaxTokens   int32         `yaml:"maxTokens"`
+	Timeout     time.Duration `yaml:"timeout"`
+}
+
+// DefaultJudgeConfig returns the Azure OpenAI deployment the AI review was built against.
+func DefaultJudgeConfig() JudgeConfig {
+	return JudgeConfig{
+		Provider:    JudgeAzureOpenAI,
+		Endpoint:    "https://openai-acl4o26y5lrhk.openai.azure.com/",
+		Deployment:  "llm-gpt-4o",
+		Temperature: 0.7,
+		TopP:        0.95,
+		MaxTokens:   800,
+		Timeout:     2 * time.Minute,
+	}
+}
+
+// LoadJudgeConfig reads a judge config file over defaults, fields missing from the file keep their
+// default value.
+func LoadJudgeConfig(file string, defaults JudgeConfig) (JudgeConfig, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return defaults, fmt.Errorf("failed to read judge config file: %w", err)
+	}
+	config := defaults
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return defaults, fmt.Errorf("failed to unmarshal judge config %s: %w", file, err)
+	}
+	return config, nil
+}
+
+// withTimeout bounds a judge call with the configured timeout. A call that times out fails with
+// context.DeadlineExceeded and is retried like a server error.
+func (config JudgeConfig) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if config.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, config.Timeout)
+}
+
+// NewIncidentJudge creates the judge selected by config.Provider.
+func NewIncidentJudge(config JudgeConfig) (IncidentJudge, error) {
+	switch config.Provider {
+	case JudgeAzureOpenAI:
+		return NewAzureOpenAIJudge(config)
+	case JudgeOpenAI:
+		if config.Endpoint == "" {
+			return nil, fmt.Errorf("an endpoint is required for provider '%s'", config.Provider)
+		}
+		return &OpenAIJudge{config: config, client: &http.Client{}}, nil
+	case JudgeFake:
+		return &FakeJudge{}, nil
+	default:
+		return nil, fmt.Errorf("unknown judge provider '%s', expected %s, %s or %s", config.Provider, JudgeAzureOpenAI, JudgeOpenAI, JudgeFake)
+	}
+}
+
+// AzureOpenAIJudge calls an Azure OpenAI deployment using the default Azure credential (managed identity, az login...).
+type AzureOpenAIJudge struct {
+	config JudgeConfig
+	client *azopenai.Client
+}
+
+func NewAzureOpenAIJudge(config JudgeConfig) (*AzureOpenAIJudge, error) {
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure credential: %w", err)
+	}
+	client, err := azopenai.NewClient(config.Endpoint, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure OpenAI client: %w", err)
+	}
+	return &AzureOpenAIJudge{config: config, client: client}, nil
+}
+
+func (j *AzureOpenAIJudge) Name() string {
+	return fmt.Sprintf("%s/%s", JudgeAzureOpenAI, j.config.Deployment)
+}
+
+func (j *AzureOpenAIJudge) Complete(ctx context.Context, messages []JudgeMessage, responseSchema string) (string, error) {
+	deploymentName := j.config.Deployment
+	maxTokens := j.config.MaxTokens
+	temperature := j.config.Temperature
+	topP := j.config.TopP
+	frequencyPenalty := float32(0)
+	presencePenalty := float32(0)
+
+	requestMessages := []azopenai.ChatRequestMessageClassification{}
+	for _, message := range messages {
+		switch message.Role {
+		case RoleSystem:
+			requestMessages = append(requestMessages, &azopenai.ChatRequestSystemMessage{Content: azopenai.NewChatRequestSystemMessageContent(message.Content)})
+		case RoleAssistant:
+			requestMessages = append(requestMessages, &azopenai.ChatRequestAssistantMessage{Content: azopenai.NewChatRequestAssistantMessageContent(message.Content)})
+		default:
+			requestMessages = append(requestMessages, &azopenai.ChatRequestUserMessage{Content: azopenai.NewChatRequestUserMessageContent(message.Content)})
+		}
+	}
+
+	options := azopenai.ChatCompletionsOptions{
+		Messages:         requestMessages,
+		DeploymentName:   &deploymentName,
+		MaxTokens:        &maxTokens,
+		Temperature:      &temperature,
+		TopP:             &topP,
+		FrequencyPenalty: &frequencyPenalty,
+		PresencePenalty:  &presencePenalty,
+	}
+	if responseSchema != "" {
+		schemaName := VerdictSchemaName
+		strict := true
+		options.ResponseFormat = &azopenai.ChatCompletionsJSONSchemaResponseFormat{
+			JSONSchema: &azopenai.ChatCompletionsJSONSchemaResponseFormatJSONSchema{
+				Name:   &schemaName,
+				Schema: []byte(responseSchema),
+				Strict: &strict,
+			},
+		}
+	}
+
+	ctx, cancel := j.config.withTimeout(ctx)
+	defer cancel()
+	resp, err := j.client.GetChatCompletions(ctx, options, nil)
+	if err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) {
+			return "", &JudgeError{StatusCode: respErr.StatusCode, Err: err}
+		}
+		return "", err
+	}
+
+	for _, choice := range resp.Choices {
+		if choice.Message != nil && choice.Message.Content != nil {
+			return *choice.Message.Content, nil
+		}
+	}
+	return "", fmt.Errorf("no content in response from deployment '%s'", deploymentName)
+}
+
+// OpenAIJudge calls an OpenAI-compatible chat completions endpoint, such as a local model server or a mock.
+// Endpoint is the API base URL, e.g. "http://localhost:8080/v1".
+type OpenAIJudge struct {
+	config JudgeConfig
+	client *http.Client
+}
+
+type openAIJSONSchema struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict bool            `json:"strict"`
+}
+
+type openAIResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *openAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openAIRequest struct {
+	Model          string                `json:"model"`
+	Messages       []JudgeMessage        `json:"messages"`
+	Temperature    float32               `json:"temperature"`
+	TopP           float32               `json:"top_p"`
+	MaxTokens      int32                 `json:"max_tokens"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message JudgeMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (j *OpenAIJudge) Name() string {
+	return fmt.Sprintf("%s/%s", JudgeOpenAI, j.config.Deployment)
+}
+
+func (j *OpenAIJudge) Complete(ctx context.Context, messages []JudgeMessage, responseSchema string) (string, error) {
+	request := openAIRequest{
+		Model:       j.config.Deployment,
+		Messages:    messages,
+		Temperature: j.config.Temperature,
+		TopP:        j.config.TopP,
+		MaxTokens:   j.config.MaxTokens,
+	}
+	if responseSchema != "" {
+		request.ResponseFormat = &openAIResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &openAIJSONSchema{Name: VerdictSchemaName, Schema: json.RawMessage(responseSchema), Strict: true},
+		}
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := strings.TrimSuffix(j.config.Endpoint, "/") + "/chat/completions"
+	ctx, cancel := j.config.withTimeout(ctx)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if j.config.ApiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+j.config.ApiKey)
+	}
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", &JudgeError{StatusCode: resp.StatusCode, Err: fmt.Errorf("%s returned %s: %s", url, resp.Status, data)}
+	}
+
+	var completion openAIResponse
+	if err := json.Unmarshal(data, &completion); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("no choices in response from %s", url)
+	}
+	return completion.Choices[0].Message.Content, nil
+}
+
+// FakeJudge is a deterministic judge for tests and dry runs. It rejects incidents whose prompt
+// mentions a README or Markdown file and accepts everything else.
+type FakeJudge struct{}
+
+func (j *FakeJudge) Name() string {
+	return string(JudgeFake)
+}
+
+func (j *FakeJudge) Complete(ctx context.Context, messages []JudgeMessage, responseSchema string) (string, error) {
+	lower := ""
+	if len(messages) > 0 {
+		lower = strings.ToLower(messages[len(messages)-1].Content)
+	}
+	if strings.Contains(lower, "readme") || strings.Contains(lower, ".md\n") {
+		return `{"verdict": "false_positive", "reason": "Code match is in a README or documentation file.", "category": "documentation", "confidence": 1}`, nil
+	}
+	return `{"verdict": "true_positive", "reason": "", "category": "none", "confidence": 1}`, nil
+}
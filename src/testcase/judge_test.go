@@ -0,0 +1,152 @@
+package testcase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunAIReviewFakeJudge(t *testing.T) {
+	tc := TestCase{Name: "review", OutputFolder: t.TempDir(), Judge: &FakeJudge{}, AIParallelism: 2}
+	incidents := map[string]ValidateIncident{
+		"b-code": {RuleSet: "azure/java", Rule: "rule-01", Uri: "file:///repos/review/src/main/java/App.java", Message: "Uses a local file.", LineNumber: 3},
+		"a-docs": {RuleSet: "azure/java", Rule: "rule-01", Uri: "file:///repos/review/README.md", Message: "Uses a local file.", LineNumber: 1},
+	}
+	verdicts, err := tc.RunAIReview(incidents)
+	if err != nil {
+		t.Fatalf("RunAIReview: %v", err)
+	}
+	if len(verdicts) != 2 || verdicts[0].Key != "a-docs" || verdicts[1].Key != "b-code" {
+		t.Fatalf("verdicts are not ordered by key: %+v", verdicts)
+	}
+	if !verdicts[0].IsFalsePositive() || verdicts[0].Category != CategoryDocumentation || verdicts[0].Judge != string(JudgeFake) {
+		t.Errorf("README incident verdict = %+v", verdicts[0])
+	}
+	if !verdicts[1].IsTruePositive() || verdicts[1].Failed() {
+		t.Errorf("source incident verdict = %+v", verdicts[1])
+	}
+	if _, err := os.Stat(tc.getAIReviewFile()); err != nil {
+		t.Errorf("AI review file not written: %v", err)
+	}
+	if _, err := os.Stat(tc.getAIReviewProgressFile()); !os.IsNotExist(err) {
+		t.Errorf("progress file kept after a complete review: %v", err)
+	}
+}
+
+// newChatServer answers chat completion requests with handle, after checking the request.
+func newChatServer(t *testing.T, handle func(w http.ResponseWriter, request openAIRequest)) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var request openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		handle(w, request)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestOpenAIJudge(t *testing.T, endpoint string, timeout time.Duration) IncidentJudge {
+	t.Helper()
+	judge, err := NewIncidentJudge(JudgeConfig{Provider: JudgeOpenAI, Endpoint: endpoint + "/v1/", Deployment: "model", ApiKey: "key", MaxTokens: 100, Timeout: timeout})
+	if err != nil {
+		t.Fatalf("NewIncidentJudge: %v", err)
+	}
+	return judge
+}
+
+func TestOpenAIJudgeComplete(t *testing.T) {
+	server := newChatServer(t, func(w http.ResponseWriter, request openAIRequest) {
+		if request.Model != "model" || request.MaxTokens != 100 || len(request.Messages) != 2 {
+			t.Errorf("unexpected request: %+v", request)
+		}
+		if request.ResponseFormat == nil || request.ResponseFormat.JSONSchema.Name != VerdictSchemaName {
+			t.Errorf("response format = %+v", request.ResponseFormat)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"choices": []any{map[string]any{"message": JudgeMessage{Role: RoleAssistant, Content: validVerdict}}}})
+	})
+	judge := newTestOpenAIJudge(t, server.URL, time.Second)
+	if judge.Name() != "openai/model" {
+		t.Errorf("Name = %s", judge.Name())
+	}
+	messages := []JudgeMessage{{Role: RoleSystem, Content: "system"}, {Role: RoleUser, Content: "incident"}}
+	content, err := judge.Complete(context.Background(), messages, VerdictSchema)
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if content != validVerdict {
+		t.Errorf("Complete = %s", content)
+	}
+}
+
+func TestOpenAIJudgeErrorStatus(t *testing.T) {
+	server := newChatServer(t, func(w http.ResponseWriter, request openAIRequest) {
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	})
+	_, err := newTestOpenAIJudge(t, server.URL, time.Second).Complete(context.Background(), nil, "")
+	var judgeErr *JudgeError
+	if !errors.As(err, &judgeErr) || judgeErr.StatusCode != http.StatusTooManyRequests || !isRetryable(err) {
+		t.Errorf("Complete error = %v, want a retryable 429 JudgeError", err)
+	}
+}
+
+func TestOpenAIJudgeTimeoutRetried(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	defer close(release)
+	server := newChatServer(t, func(w http.ResponseWriter, request openAIRequest) {
+		// The first call hangs until the end of the test
+		if calls.Add(1) == 1 {
+			<-release
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"choices": []any{map[string]any{"message": JudgeMessage{Role: RoleAssistant, Content: validVerdict}}}})
+	})
+	judge := newTestOpenAIJudge(t, server.URL, 100*time.Millisecond)
+
+	_, err := judge.Complete(context.Background(), nil, "")
+	if !errors.Is(err, context.DeadlineExceeded) || !isRetryable(err) {
+		t.Fatalf("Complete error = %v, want a retryable timeout", err)
+	}
+
+	calls.Store(0)
+	rateLimited := NewRateLimitedJudge(judge, RateLimitConfig{MaxRetries: 1, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}, 100)
+	content, err := rateLimited.Complete(context.Background(), nil, "")
+	if err != nil {
+		t.Fatalf("Complete after retry: %v", err)
+	}
+	if content != validVerdict || calls.Load() != 2 {
+		t.Errorf("Complete = %q after %d calls, want the verdict after 2 calls", content, calls.Load())
+	}
+}
+
+func TestLoadJudgeConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "judge.yaml")
+	if err := os.WriteFile(file, []byte("provider: openai\nendpoint: http://localhost:8080/v1\ntimeout: 30s\n"), 0644); err != nil {
+		t.Fatalf("writing config: %v", err)
+	}
+	config, err := LoadJudgeConfig(file, DefaultJudgeConfig())
+	if err != nil {
+		t.Fatalf("LoadJudgeConfig: %v", err)
+	}
+	defaults := DefaultJudgeConfig()
+	if config.Provider != JudgeOpenAI || config.Timeout != 30*time.Second || !strings.HasPrefix(config.Endpoint, "http://localhost") ||
+		config.Deployment != defaults.Deployment || config.MaxTokens != defaults.MaxTokens {
+		t.Errorf("LoadJudgeConfig = %+v", config)
+	}
+}
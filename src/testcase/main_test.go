@@ -0,0 +1,14 @@
+package testcase
+
+import (
+	"io"
+	"lianwMS/appcat_validation/logger"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitWriter(io.Discard, logger.Options{Level: slog.LevelError})
+	os.Exit(m.Run())
+}
@@ -0,0 +1,128 @@
+package testcase
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+func init() {
+	// Incident variables are decoded from YAML into interface values
+	gob.Register(map[string]interface{}{})
+	gob.Register(map[interface{}]interface{}{})
+	gob.Register([]interface{}{})
+	gob.Register(time.Time{})
+}
+
+// ParsedOutput is an AppCat output parsed by ParseAppCatOutput. It is shared between the steps of
+// a run and must not be modified.
+type ParsedOutput struct {
+	Incidents   map[string]ValidateIncident
+	RuleDetails map[string]int
+	Count       int
+	Coverage    ProjectCoverage
+}
+
+// OutputCache is an on-disk cache of parsed AppCat outputs. Each output is stored as <hash>.gob
+// where the hash covers the project name and the output files, so an unchanged output, like a
+// baseline, is parsed once across runs.
+type OutputCache struct {
+	folder string
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewOutputCache opens the cache in folder, creating the folder if needed.
+func NewOutputCache(folder string) (*OutputCache, error) {
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output cache folder: %w", err)
+	}
+	return &OutputCache{folder: folder}, nil
+}
+
+func (c *OutputCache) getFile(key string) string {
+	return filepath.Join(c.folder, fmt.Sprintf("%s%s", key, GobExtension))
+}
+
+// Get returns the cached output for the output hash key and records a hit or a miss.
+func (c *OutputCache) Get(key string) (ParsedOutput, bool) {
+	var parsed ParsedOutput
+	data, err := os.ReadFile(c.getFile(key))
+	if err == nil {
+		err = gob.NewDecoder(bytes.NewReader(data)).Decode(&parsed)
+	}
+	if err != nil {
+		c.misses.Add(1)
+		return ParsedOutput{}, false
+	}
+	c.hits.Add(1)
+	return parsed, true
+}
+
+// Put stores the parsed output for key.
+func (c *OutputCache) Put(key string, parsed ParsedOutput) error {
+	var data bytes.Buffer
+	if err := gob.NewEncoder(&data).Encode(parsed); err != nil {
+		return fmt.Errorf("failed to encode parsed output: %w", err)
+	}
+	if err := os.WriteFile(c.getFile(key), data.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write output cache file: %w", err)
+	}
+	return nil
+}
+
+// Stats returns the number of cache hits and misses so far.
+func (c *OutputCache) Stats() (int64, int64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+// outputFiles lists the files incidents are read from in outputPath: output.yaml, or the static
+// report data when output.yaml is missing or empty.
+func outputFiles(outputPath string) []string {
+	outputFile := filepath.Join(outputPath, OutputFileName)
+	info, err := os.Stat(outputFile)
+	if err == nil && info.Size() > 0 {
+		return []string{outputFile}
+	}
+	if files := staticReportDataFiles(outputPath); len(files) > 0 {
+		return files
+	}
+	if err == nil {
+		return []string{outputFile}
+	}
+	return nil
+}
+
+// outputCacheVersion changes with ParsedOutput, so outputs cached by older versions are parsed again.
+const outputCacheVersion = "2"
+
+// outputHash hashes the project name with the names and contents of the output files in
+// outputPath, as incident keys depend on both. It returns "" when there is no output file.
+func (tc *TestCase) outputHash(outputPath string) (string, error) {
+	files := outputFiles(outputPath)
+	if len(files) == 0 {
+		return "", nil
+	}
+	hash := sha256.New()
+	hash.Write([]byte(outputCacheVersion + "\x00" + tc.Name + "\x00"))
+	for _, file := range files {
+		hash.Write([]byte(filepath.Base(file) + "\x00"))
+		data, err := os.Open(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read output file: %w", err)
+		}
+		_, err = io.Copy(hash, data)
+		data.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to read output file: %w", err)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,53 @@
+package testcase
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseOutputOncePerRun(t *testing.T) {
+	folder := t.TempDir()
+	outputFile := filepath.Join(folder, OutputFileName)
+	if err := os.WriteFile(outputFile, generateOutput(1, 2, 3), 0644); err != nil {
+		t.Fatalf("writing output: %v", err)
+	}
+	tc := TestCase{Name: "once", parsed: make(map[string]ParsedOutput)}
+	first, err := tc.parseOutput(folder)
+	if err != nil {
+		t.Fatalf("parseOutput: %v", err)
+	}
+	// The output is neither read nor hashed again in the same run
+	if err := os.Remove(outputFile); err != nil {
+		t.Fatalf("removing output: %v", err)
+	}
+	second, err := tc.parseOutput(folder + string(filepath.Separator))
+	if err != nil {
+		t.Fatalf("parseOutput after removing the output: %v", err)
+	}
+	if first.Count != 6 || second.Count != first.Count {
+		t.Errorf("parsed %d then %d incidents, want 6", first.Count, second.Count)
+	}
+}
+
+func TestOutputCacheAcrossRuns(t *testing.T) {
+	folder := t.TempDir()
+	if err := os.WriteFile(filepath.Join(folder, OutputFileName), generateOutput(1, 2, 3), 0644); err != nil {
+		t.Fatalf("writing output: %v", err)
+	}
+	cache, err := NewOutputCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewOutputCache: %v", err)
+	}
+	for run := 0; run < 2; run++ {
+		tc := TestCase{Name: "cached", OutputCache: cache, parsed: make(map[string]ParsedOutput)}
+		for step := 0; step < 3; step++ {
+			if _, err := tc.parseOutput(folder); err != nil {
+				t.Fatalf("parseOutput: %v", err)
+			}
+		}
+	}
+	if hits, misses := cache.Stats(); hits != 1 || misses != 1 {
+		t.Errorf("output cache: %d hits, %d misses, want 1 hit and 1 miss", hits, misses)
+	}
+}
@@ -0,0 +1,145 @@
+package testcase
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OutputDecoder decodes an AppCat output.yaml one ruleset at a time, so memory is bounded by the
+// largest ruleset instead of the whole document. output.yaml is a block sequence of rulesets: each
+// top level "- " entry is read as a chunk of lines and decoded on its own.
+type OutputDecoder struct {
+	reader *bufio.Reader
+	// line is the line number of the next line to read, starting at 1.
+	line int
+	// next is the first line of the next entry, read while looking for the end of the current one.
+	next     string
+	nextLine int
+	// pending holds the rulesets of a document that is not a block sequence, decoded at once.
+	pending []RuleSet
+	done    bool
+}
+
+func NewOutputDecoder(reader io.Reader) *OutputDecoder {
+	return &OutputDecoder{reader: bufio.NewReaderSize(reader, 1024*1024), line: 1}
+}
+
+// readLine returns the next line without its line delimiter, or io.EOF after the last line.
+func (d *OutputDecoder) readLine() (string, error) {
+	line, err := d.reader.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return "", io.EOF
+	}
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	d.line++
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// isEntryStart reports whether line starts a top level sequence entry.
+func isEntryStart(line string) bool {
+	return line == "-" || strings.HasPrefix(line, "- ")
+}
+
+// isDocumentEnd reports whether line ends the YAML document, by a document end or the start of
+// another document.
+func isDocumentEnd(line string) bool {
+	return line == "..." || line == "---" || strings.HasPrefix(line, "--- ")
+}
+
+// Next returns the next ruleset, or io.EOF after the last one.
+func (d *OutputDecoder) Next() (*RuleSet, error) {
+	if len(d.pending) > 0 {
+		ruleSet := d.pending[0]
+		d.pending = d.pending[1:]
+		return &ruleSet, nil
+	}
+	if d.done {
+		return nil, io.EOF
+	}
+
+	// Find the first line of the entry, skipping the document start, comments and empty lines
+	start, startLine := d.next, d.nextLine
+	d.next = ""
+	for start == "" {
+		line, err := d.readLine()
+		if err == io.EOF {
+			d.done = true
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read output: %w", err)
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || line == "---" {
+			continue
+		}
+		if line == "..." {
+			d.done = true
+			return nil, io.EOF
+		}
+		if !isEntryStart(line) {
+			// Not a block sequence, e.g. "[]" for an empty output
+			return d.decodeRest(line, d.line-1)
+		}
+		start, startLine = line, d.line-1
+	}
+
+	chunk := strings.Builder{}
+	chunk.WriteString(start)
+	chunk.WriteString(lineDelimiter)
+	for {
+		line, err := d.readLine()
+		if err == io.EOF || (err == nil && isDocumentEnd(line)) {
+			d.done = true
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read output: %w", err)
+		}
+		if isEntryStart(line) {
+			d.next, d.nextLine = line, d.line-1
+			break
+		}
+		chunk.WriteString(line)
+		chunk.WriteString(lineDelimiter)
+	}
+	return decodeRuleSet(chunk.String(), startLine)
+}
+
+// decodeRuleSet decodes the sequence entry chunk starting at line startLine into a ruleset.
+func decodeRuleSet(chunk string, startLine int) (*RuleSet, error) {
+	var node yaml.Node
+	if err := yaml.NewDecoder(strings.NewReader(chunk)).Decode(&node); err != nil {
+		return nil, fmt.Errorf("failed to parse ruleset at line %d: %w", startLine, err)
+	}
+	if len(node.Content) != 1 || node.Content[0].Kind != yaml.SequenceNode || len(node.Content[0].Content) != 1 {
+		return nil, fmt.Errorf("failed to parse ruleset at line %d: not a sequence entry", startLine)
+	}
+	var ruleSet RuleSet
+	if err := node.Content[0].Content[0].Decode(&ruleSet); err != nil {
+		return nil, fmt.Errorf("failed to parse ruleset at line %d: %w", startLine, err)
+	}
+	return &ruleSet, nil
+}
+
+// decodeRest decodes the rest of a document that is not a block sequence, starting with line.
+func (d *OutputDecoder) decodeRest(line string, startLine int) (*RuleSet, error) {
+	d.done = true
+	rest, err := io.ReadAll(d.reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output: %w", err)
+	}
+	var ruleSets []RuleSet
+	if err := yaml.NewDecoder(strings.NewReader(line + lineDelimiter + string(rest))).Decode(&ruleSets); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse output at line %d: %w", startLine, err)
+	}
+	d.pending = ruleSets
+	return d.Next()
+}
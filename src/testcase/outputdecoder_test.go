@@ -0,0 +1,189 @@
+package testcase
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Size of the synthetic output of the benchmarks: 10 rulesets of 20 rules of 520 incidents.
+const (
+	benchRuleSets         = 10
+	benchRules            = 20
+	benchIncidentsPerRule = 520
+)
+
+// generateOutput writes a synthetic output.yaml in the layout AppCat writes, with multi-line
+// messages and code snippets, variables, and unmatched and skipped rules.
+func generateOutput(ruleSets int, rules int, incidentsPerRule int) []byte {
+	var output bytes.Buffer
+	for ruleSet := 0; ruleSet < ruleSets; ruleSet++ {
+		fmt.Fprintf(&output, "- name: bench/ruleset-%02d\n", ruleSet)
+		fmt.Fprintf(&output, "  description: Synthetic ruleset %d\n", ruleSet)
+		output.WriteString("  violations:\n")
+		for rule := 0; rule < rules; rule++ {
+			fmt.Fprintf(&output, "    bench-rule-%02d-%03d:\n", ruleSet, rule)
+			fmt.Fprintf(&output, "      description: Synthetic rule %d\n", rule)
+			output.WriteString("      category: potential\n")
+			output.WriteString("      labels:\n        - konveyor.io/source\n        - konveyor.io/target=azure-aks\n")
+			output.WriteString("      incidents:\n")
+			for incident := 0; incident < incidentsPerRule; incident++ {
+				fmt.Fprintf(&output, "        - uri: file:///C:/repos/bench/src/main/java/bench/File%d.java\n", incident)
+				output.WriteString("          message: |-\n            The application uses a synthetic API.\n            Replace it before migrating.\n")
+				fmt.Fprintf(&output, "          codeSnip: |2-\n             %d  import bench.Api;\n             %d  Api.call();\n", incident+1, incident+2)
+				fmt.Fprintf(&output, "          lineNumber: %d\n", incident+2)
+				output.WriteString("          variables:\n            matchingText: bench.Api\n")
+			}
+			output.WriteString("      links:\n        - url: https://example.com/bench\n          title: Bench\n")
+			output.WriteString("      effort: 3\n")
+		}
+		output.WriteString("  unmatched:\n    - bench-unmatched-01\n")
+		output.WriteString("  skipped:\n    - bench-skipped-01\n")
+	}
+	return output.Bytes()
+}
+
+// decodeAll decodes every ruleset of data with OutputDecoder.
+func decodeAll(t testing.TB, data []byte) []RuleSet {
+	t.Helper()
+	ruleSets := []RuleSet{}
+	decoder := NewOutputDecoder(bytes.NewReader(data))
+	for {
+		ruleSet, err := decoder.Next()
+		if err == io.EOF {
+			return ruleSets
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		ruleSets = append(ruleSets, *ruleSet)
+	}
+}
+
+func TestOutputDecoderMatchesUnmarshal(t *testing.T) {
+	inputs := map[string][]byte{
+		"synthetic":      generateOutput(3, 4, 5),
+		"document start": append([]byte("---\n# comment\n"), generateOutput(2, 2, 2)...),
+		"document end":   append(generateOutput(2, 2, 2), []byte("...\n")...),
+		"crlf":           bytes.ReplaceAll(generateOutput(2, 2, 2), []byte("\n"), []byte("\r\n")),
+		"empty":          []byte("[]\n"),
+	}
+	baselines, _ := filepath.Glob(filepath.Join("..", "..", "data", "baseline", "*", "appcat_output", OutputFileName))
+	for _, file := range baselines {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("reading %s: %v", file, err)
+		}
+		inputs[file] = data
+	}
+
+	for name, data := range inputs {
+		t.Run(name, func(t *testing.T) {
+			want := []RuleSet{}
+			if err := yaml.Unmarshal(data, &want); err != nil {
+				t.Fatalf("yaml.Unmarshal: %v", err)
+			}
+			got := decodeAll(t, data)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("OutputDecoder decoded %d rulesets differing from yaml.Unmarshal (%d rulesets)", len(got), len(want))
+			}
+		})
+	}
+}
+
+// liveHeap returns the heap in use after a garbage collection.
+func liveHeap() uint64 {
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// reportLiveHeap reports the largest heap kept alive above start while decoding, in MB.
+func reportLiveHeap(b *testing.B, start uint64, peak uint64) {
+	if peak > start {
+		b.ReportMetric(float64(peak-start)/(1<<20), "live-MB")
+	}
+}
+
+// BenchmarkDecodeOutput also reports the largest heap kept alive while decoding, measured after each
+// ruleset with the ruleset still referenced, bounded by one ruleset rather than the whole output.
+func BenchmarkDecodeOutput(b *testing.B) {
+	data := generateOutput(benchRuleSets, benchRules, benchIncidentsPerRule)
+	start, peak := liveHeap(), uint64(0)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		incidents := 0
+		decoder := NewOutputDecoder(bytes.NewReader(data))
+		for {
+			ruleSet, err := decoder.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatalf("Next: %v", err)
+			}
+			for _, violation := range ruleSet.Violations {
+				incidents += len(violation.Incidents)
+			}
+			b.StopTimer()
+			peak = max(peak, liveHeap())
+			runtime.KeepAlive(ruleSet)
+			b.StartTimer()
+		}
+		if incidents != benchRuleSets*benchRules*benchIncidentsPerRule {
+			b.Fatalf("decoded %d incidents", incidents)
+		}
+	}
+	reportLiveHeap(b, start, peak)
+}
+
+func BenchmarkParseOutput(b *testing.B) {
+	folder := b.TempDir()
+	data := generateOutput(benchRuleSets, benchRules, benchIncidentsPerRule)
+	if err := os.WriteFile(filepath.Join(folder, OutputFileName), data, 0644); err != nil {
+		b.Fatalf("writing output: %v", err)
+	}
+	tc := TestCase{Name: "bench"}
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, count, err := tc.ParseAppCatOutput(folder, "")
+		if err != nil {
+			b.Fatalf("ParseAppCatOutput: %v", err)
+		}
+		if count != benchRuleSets*benchRules*benchIncidentsPerRule {
+			b.Fatalf("parsed %d incidents", count)
+		}
+	}
+}
+
+// BenchmarkUnmarshalOutput decodes the same output as BenchmarkDecodeOutput at once, for comparison.
+func BenchmarkUnmarshalOutput(b *testing.B) {
+	data := generateOutput(benchRuleSets, benchRules, benchIncidentsPerRule)
+	start, peak := liveHeap(), uint64(0)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ruleSets := []RuleSet{}
+		if err := yaml.Unmarshal(data, &ruleSets); err != nil {
+			b.Fatalf("yaml.Unmarshal: %v", err)
+		}
+		b.StopTimer()
+		peak = max(peak, liveHeap())
+		runtime.KeepAlive(ruleSets)
+		b.StartTimer()
+	}
+	reportLiveHeap(b, start, peak)
+}
@@ -0,0 +1,68 @@
+package testcase
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RuleCounts counts incidents, or diffs, by rule.
+type RuleCounts map[RuleRef]int
+
+// CountIncidentRules counts the incidents by rule.
+func CountIncidentRules(incidents map[string]ValidateIncident) RuleCounts {
+	counts := make(RuleCounts)
+	for _, incident := range incidents {
+		counts[RuleRef{RuleSet: incident.RuleSet, Rule: incident.Rule}]++
+	}
+	return counts
+}
+
+// CountDiffRules counts the validation diffs by rule.
+func CountDiffRules(diffs map[string]ValidationDiff) RuleCounts {
+	counts := make(RuleCounts)
+	for _, diff := range diffs {
+		counts[RuleRef{RuleSet: diff.Incident.RuleSet, Rule: diff.Incident.Rule}]++
+	}
+	return counts
+}
+
+// RulePivot returns the CSV rows of the rule × project pivot of counts: one row per ruleset and
+// rule, one column per project in the given order, zero when a project has no count for the rule,
+// with a total column and a total row.
+func RulePivot(counts map[string]RuleCounts, projects []string) [][]string {
+	rules := make(map[RuleRef]bool)
+	for _, projectCounts := range counts {
+		for rule := range projectCounts {
+			rules[rule] = true
+		}
+	}
+	sortedRules := slices.SortedFunc(maps.Keys(rules), func(a, b RuleRef) int {
+		if a.RuleSet != b.RuleSet {
+			return strings.Compare(a.RuleSet, b.RuleSet)
+		}
+		return strings.Compare(a.Rule, b.Rule)
+	})
+
+	rows := [][]string{append(append([]string{"RuleSet", "Rule"}, projects...), "Total")}
+	projectTotals := make([]int, len(projects))
+	for _, rule := range sortedRules {
+		row := []string{rule.RuleSet, rule.Rule}
+		total := 0
+		for index, project := range projects {
+			count := counts[project][rule]
+			total += count
+			projectTotals[index] += count
+			row = append(row, strconv.Itoa(count))
+		}
+		rows = append(rows, append(row, strconv.Itoa(total)))
+	}
+	totalRow := []string{"Total", ""}
+	total := 0
+	for _, count := range projectTotals {
+		total += count
+		totalRow = append(totalRow, strconv.Itoa(count))
+	}
+	return append(rows, append(totalRow, strconv.Itoa(total)))
+}
@@ -0,0 +1,179 @@
+package testcase
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed prompts/default.yaml
+var defaultPromptTemplate []byte
+
+// PromptTemplate holds the system prompt and the user prompt template of the AI review.
+// User is a text/template executed with PromptData for each incident.
+type PromptTemplate struct {
+	Version string `yaml:"version"`
+	System  string `yaml:"system"`
+	User    string `yaml:"user"`
+
+	user *template.Template
+	id   string
+}
+
+// PromptData is the data available to the user prompt template.
+type PromptData struct {
+	Key         string
+	RuleSet     string
+	Rule        string
+	Description string
+	Category    string
+	Effort      int
+	Labels      []string
+	Links       []Link
+	// Uri is relative to the project, see TestCase.relativeUri.
+	Uri        string
+	Message    string
+	CodeSnip   string
+	LineNumber int
+	Variables  interface{}
+	// Incident is the YAML of the fields described in the system prompt.
+	Incident string
+	// Source holds the lines around the match read from the project, when enabled.
+	Source []SourceLine
+}
+
+// SourceLine is one line of the project source around an incident.
+type SourceLine struct {
+	Number int
+	Text   string
+	Match  bool
+}
+
+// promptIncident is the subset of ValidateIncident rendered as the [Tool Result].
+type promptIncident struct {
+	Uri        string      `yaml:"uri"`
+	Message    string      `yaml:"message"`
+	CodeSnip   string      `yaml:"codeSnip"`
+	LineNumber int         `yaml:"lineNumber"`
+	Variables  interface{} `yaml:"variables"`
+}
+
+// DefaultPromptTemplate returns the prompt template shipped in prompts/default.yaml.
+func DefaultPromptTemplate() (*PromptTemplate, error) {
+	return parsePromptTemplate(defaultPromptTemplate, "default")
+}
+
+// LoadPromptTemplate reads a prompt template file with the same layout as prompts/default.yaml.
+func LoadPromptTemplate(file string) (*PromptTemplate, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt template file: %w", err)
+	}
+	return parsePromptTemplate(data, filepath.Base(file))
+}
+
+func parsePromptTemplate(data []byte, name string) (*PromptTemplate, error) {
+	var prompt PromptTemplate
+	if err := yaml.Unmarshal(data, &prompt); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal prompt template %s: %w", name, err)
+	}
+	if prompt.Version == "" || prompt.System == "" || prompt.User == "" {
+		return nil, fmt.Errorf("prompt template %s requires version, system and user", name)
+	}
+	user, err := template.New(name).Option("missingkey=error").Parse(prompt.User)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse user prompt template %s: %w", name, err)
+	}
+	prompt.user = user
+	// The content hash tells revisions apart even if the version was not changed after an edit
+	hash := sha256.Sum256([]byte(prompt.System + "\x00" + prompt.User))
+	prompt.id = fmt.Sprintf("%s-%s", prompt.Version, hex.EncodeToString(hash[:])[:8])
+	return &prompt, nil
+}
+
+// ID identifies the prompt revision as "<version>-<content hash>". It is recorded in every verdict
+// and is part of the verdict cache key.
+func (p *PromptTemplate) ID() string {
+	return p.id
+}
+
+// Render executes the user prompt template for one incident.
+func (p *PromptTemplate) Render(data PromptData) (string, error) {
+	var buffer bytes.Buffer
+	if err := p.user.Execute(&buffer, data); err != nil {
+		return "", fmt.Errorf("failed to render user prompt: %w", err)
+	}
+	return buffer.String(), nil
+}
+
+// promptData builds the template data of an incident, reading contextLines source lines before
+// and after the match from the project folder when contextLines is positive.
+func (tc *TestCase) promptData(key string, incident ValidateIncident, contextLines int) (PromptData, error) {
+	uri := tc.relativeUri(incident.Uri)
+	incidentData, err := yaml.Marshal(promptIncident{
+		Uri:        uri,
+		Message:    incident.Message,
+		CodeSnip:   incident.CodeSnip,
+		LineNumber: incident.LineNumber,
+		Variables:  incident.Variables,
+	})
+	if err != nil {
+		return PromptData{}, fmt.Errorf("failed to marshal incident: %w", err)
+	}
+	data := PromptData{
+		Key:         key,
+		RuleSet:     incident.RuleSet,
+		Rule:        incident.Rule,
+		Description: incident.Description,
+		Category:    incident.Category,
+		Effort:      incident.Effort,
+		Labels:      incident.Labels,
+		Links:       incident.Links,
+		Uri:         uri,
+		Message:     incident.Message,
+		CodeSnip:    incident.CodeSnip,
+		LineNumber:  incident.LineNumber,
+		Variables:   incident.Variables,
+		Incident:    string(incidentData),
+	}
+	if contextLines > 0 && incident.LineNumber > 0 {
+		// Missing sources only lose the context, the incident itself is still reviewed
+		data.Source, _ = tc.readSourceLines(uri, incident.LineNumber, contextLines)
+	}
+	return data, nil
+}
+
+// readSourceLines reads the lines around lineNumber of the project file at the relative uri.
+func (tc *TestCase) readSourceLines(uri string, lineNumber int, contextLines int) ([]SourceLine, error) {
+	relative := strings.TrimPrefix(strings.ReplaceAll(uri, "\\", "/"), tc.Name+"/")
+	if relative == "" || strings.Contains(relative, "://") {
+		return nil, fmt.Errorf("uri %s is not in project %s", uri, tc.Name)
+	}
+	file, err := os.Open(filepath.Join(tc.ProjectFolder, filepath.FromSlash(relative)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer file.Close()
+
+	lines := []SourceLine{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for number := 1; scanner.Scan() && number <= lineNumber+contextLines; number++ {
+		if number >= lineNumber-contextLines {
+			lines = append(lines, SourceLine{Number: number, Text: scanner.Text(), Match: number == lineNumber})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read source file: %w", err)
+	}
+	return lines, nil
+}
@@ -0,0 +1,115 @@
+package testcase
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// IncidentFilter selects incidents of an IncidentStore for the query command. Empty fields match
+// any incident.
+type IncidentFilter struct {
+	RuleSet string
+	// Rule matches the rule ID anywhere, anchor it with ^ and $ for an exact match.
+	Rule     *regexp.Regexp
+	Category string
+	// MinEffort and MaxEffort bound the effort, MaxEffort < 0 for no upper bound.
+	MinEffort int
+	MaxEffort int
+	Label     string
+	// File is a glob matched against the file relative to the project, e.g. "hellojava/src/*/*.java",
+	// the same path without the project folder, or the file name when it has no "/", e.g. "*.xml".
+	File string
+	// Message matches message text case-insensitively.
+	Message string
+}
+
+// ParseEffortRange parses an effort filter: "3" for exactly 3, "3-5" for 3 to 5, "3-" for at least
+// 3 or "-5" for at most 5. An empty value matches any effort.
+func ParseEffortRange(value string) (int, int, error) {
+	if value == "" {
+		return 0, -1, nil
+	}
+	minText, maxText, isRange := strings.Cut(value, "-")
+	if !isRange {
+		maxText = minText
+	}
+	bound := func(text string, unset int) (int, error) {
+		if text == "" {
+			return unset, nil
+		}
+		number, err := strconv.Atoi(strings.TrimSpace(text))
+		if err != nil || number < 0 {
+			return 0, fmt.Errorf("invalid effort '%s', expected N, N-M, N- or -M", value)
+		}
+		return number, nil
+	}
+	minEffort, err := bound(minText, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	maxEffort, err := bound(maxText, -1)
+	if err != nil {
+		return 0, 0, err
+	}
+	if maxEffort >= 0 && maxEffort < minEffort {
+		return 0, 0, fmt.Errorf("invalid effort '%s': %d is greater than %d", value, minEffort, maxEffort)
+	}
+	return minEffort, maxEffort, nil
+}
+
+// matchesFile reports whether the glob pattern matches file, a path relative to the project folder
+// starting with the project name.
+func matchesFile(pattern string, file string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(file))
+		return matched
+	}
+	if matched, _ := path.Match(pattern, file); matched {
+		return true
+	}
+	_, inProject, _ := strings.Cut(file, "/")
+	matched, _ := path.Match(pattern, inProject)
+	return matched
+}
+
+// Matches reports whether the incident matches every field of the filter.
+func (f IncidentFilter) Matches(incident StoredIncident) bool {
+	if f.RuleSet != "" && incident.RuleSet != f.RuleSet {
+		return false
+	}
+	if f.Rule != nil && !f.Rule.MatchString(incident.Rule) {
+		return false
+	}
+	if f.Category != "" && !strings.EqualFold(incident.Category, f.Category) {
+		return false
+	}
+	if incident.Effort < f.MinEffort || (f.MaxEffort >= 0 && incident.Effort > f.MaxEffort) {
+		return false
+	}
+	if f.Label != "" && !slices.Contains(incident.Labels, f.Label) {
+		return false
+	}
+	if f.File != "" && !matchesFile(f.File, incident.File) {
+		return false
+	}
+	if f.Message != "" && !strings.Contains(strings.ToLower(incident.Message), strings.ToLower(f.Message)) {
+		return false
+	}
+	return true
+}
+
+// Find returns the incidents of the store matching the filter, in key order.
+func (s *IncidentStore) Find(filter IncidentFilter) []StoredIncident {
+	found := []StoredIncident{}
+	for _, incident := range s.incidents {
+		if filter.Matches(incident) {
+			found = append(found, incident)
+		}
+	}
+	return found
+}
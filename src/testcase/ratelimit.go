@@ -0,0 +1,145 @@
+package testcase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// JudgeError is returned by judges when the model endpoint answered with an HTTP error status.
+type JudgeError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *JudgeError) Error() string {
+	return fmt.Sprintf("status %d: %v", e.StatusCode, e.Err)
+}
+
+func (e *JudgeError) Unwrap() error {
+	return e.Err
+}
+
+// isRetryable reports whether err is a throttling (429) or server (5xx) error, or a timed out
+// call, worth retrying.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var judgeErr *JudgeError
+	if errors.As(err, &judgeErr) {
+		return judgeErr.StatusCode == http.StatusTooManyRequests || judgeErr.StatusCode >= 500
+	}
+	return false
+}
+
+// RateLimitConfig controls throttling and retries of judge calls. Zero limits mean unlimited.
+type RateLimitConfig struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+	MaxRetries        int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+}
+
+// tokenBucket is a bucket of capacity perMinute refilled continuously over a minute.
+type tokenBucket struct {
+	mu        sync.Mutex
+	perMinute float64
+	available float64
+	updated   time.Time
+}
+
+func newTokenBucket(perMinute int) *tokenBucket {
+	return &tokenBucket{perMinute: float64(perMinute), available: float64(perMinute), updated: time.Now()}
+}
+
+// Wait blocks until n tokens are available and takes them. Requests larger than the
+// bucket wait for a full bucket.
+func (b *tokenBucket) Wait(ctx context.Context, n int) error {
+	if b == nil {
+		return nil
+	}
+	need := min(float64(n), b.perMinute)
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.available = min(b.perMinute, b.available+now.Sub(b.updated).Minutes()*b.perMinute)
+		b.updated = now
+		if b.available >= need {
+			b.available -= need
+			b.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((need - b.available) / b.perMinute * float64(time.Minute))
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// RateLimitedJudge wraps a judge with request and token rate limits and retries throttled
+// or failed calls with exponential backoff. It is safe for concurrent use.
+type RateLimitedJudge struct {
+	judge     IncidentJudge
+	config    RateLimitConfig
+	maxTokens int
+	requests  *tokenBucket
+	tokens    *tokenBucket
+}
+
+// NewRateLimitedJudge wraps judge; maxTokens is the completion budget counted for each request.
+func NewRateLimitedJudge(judge IncidentJudge, config RateLimitConfig, maxTokens int) *RateLimitedJudge {
+	rateLimited := &RateLimitedJudge{judge: judge, config: config, maxTokens: maxTokens}
+	if config.RequestsPerMinute > 0 {
+		rateLimited.requests = newTokenBucket(config.RequestsPerMinute)
+	}
+	if config.TokensPerMinute > 0 {
+		rateLimited.tokens = newTokenBucket(config.TokensPerMinute)
+	}
+	return rateLimited
+}
+
+func (j *RateLimitedJudge) Name() string {
+	return j.judge.Name()
+}
+
+func (j *RateLimitedJudge) Complete(ctx context.Context, messages []JudgeMessage, responseSchema string) (string, error) {
+	// Roughly 4 characters per token for the prompt, plus the completion budget
+	promptLength := len(responseSchema)
+	for _, message := range messages {
+		promptLength += len(message.Content)
+	}
+	estimatedTokens := promptLength/4 + j.maxTokens
+	backoff := j.config.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		if err := j.requests.Wait(ctx, 1); err != nil {
+			return "", err
+		}
+		if err := j.tokens.Wait(ctx, estimatedTokens); err != nil {
+			return "", err
+		}
+
+		content, err := j.judge.Complete(ctx, messages, responseSchema)
+		if err == nil || !isRetryable(err) || attempt >= j.config.MaxRetries {
+			return content, err
+		}
+
+		// Exponential backoff with jitter
+		wait := backoff + time.Duration(rand.Int63n(int64(backoff)/2+1))
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, j.config.MaxBackoff)
+	}
+}
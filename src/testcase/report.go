@@ -0,0 +1,25 @@
+package testcase
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// WriteResultsReport writes the Markdown test results to w: the summary line, then the message of
+// each result in the order given, the catalog order of the projects.
+func WriteResultsReport(w io.Writer, summary string, results []TestResult) error {
+	var report strings.Builder
+	report.WriteString("# AppCat Test Results\n")
+	report.WriteString(summary + "\n\n")
+	for _, result := range results {
+		report.WriteString(result.Message + "\n")
+	}
+	_, err := io.WriteString(w, report.String())
+	return err
+}
+
+// WriteCSV writes the rows as CSV to w.
+func WriteCSV(w io.Writer, rows [][]string) error {
+	return csv.NewWriter(w).WriteAll(rows)
+}
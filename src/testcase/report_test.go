@@ -0,0 +1,147 @@
+package testcase
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files of the tests")
+
+const (
+	goldenProject  = "hellojava"
+	goldenBaseline = "../../data/baseline/hellojava/appcat_output"
+	goldenSummary  = "Summary: 1 projects, 1 passed, 0 failed (0 tolerated), 0 errors"
+)
+
+// goldenReports analyzes and validates the hellojava baseline against itself and returns the
+// Markdown results, incidents_summary.csv and global CSV by golden file name.
+func goldenReports(t *testing.T) map[string][]byte {
+	t.Helper()
+	tc := TestCase{
+		Name:                 goldenProject,
+		OutputFolder:         t.TempDir(),
+		BaseLineFolder:       goldenBaseline,
+		ExistingOutputFolder: goldenBaseline,
+		ActionList:           []ActionType{ActionAnalyze, ActionValidate},
+	}
+	result, err := tc.Run()
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var markdown, global bytes.Buffer
+	if err := WriteResultsReport(&markdown, goldenSummary, []TestResult{result}); err != nil {
+		t.Fatalf("WriteResultsReport: %v", err)
+	}
+	summary, err := os.ReadFile(tc.getIncidentsSummaryFile())
+	if err != nil {
+		t.Fatalf("reading incidents summary: %v", err)
+	}
+	pivot := RulePivot(map[string]RuleCounts{goldenProject: result.RuleIncidents}, []string{goldenProject})
+	if err := WriteCSV(&global, pivot); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	return map[string][]byte{
+		"results.md":            markdown.Bytes(),
+		"incidents_summary.csv": summary,
+		"global.csv":            global.Bytes(),
+	}
+}
+
+// diffProjects are the projects of the diff fixture in catalog order, which is not the name order.
+var diffProjects = []string{"zeta", "alpha"}
+
+const diffSummary = "Summary: 2 projects, 0 passed, 2 failed (0 tolerated), 0 errors"
+
+// diffReports validates the outputs of the diff fixture against their baselines, with new, changed
+// and missing incidents in each project, and returns the Markdown results and the incidents and
+// diffs pivots by golden file name.
+func diffReports(t *testing.T) map[string][]byte {
+	t.Helper()
+	results := []TestResult{}
+	incidents := make(map[string]RuleCounts)
+	diffs := make(map[string]RuleCounts)
+	for _, name := range diffProjects {
+		folder := filepath.Join("testdata", "diffs", name)
+		tc := TestCase{
+			Name:                 name,
+			OutputFolder:         t.TempDir(),
+			BaseLineFolder:       filepath.Join(folder, "baseline"),
+			ExistingOutputFolder: filepath.Join(folder, "output"),
+			ActionList:           []ActionType{ActionValidate},
+		}
+		result, err := tc.Run()
+		if err != nil {
+			t.Fatalf("Run %s: %v", name, err)
+		}
+		results = append(results, result)
+		incidents[name] = result.RuleIncidents
+		diffs[name] = CountDiffRules(result.Diffs)
+	}
+
+	var markdown, global, globalDiffs bytes.Buffer
+	if err := WriteResultsReport(&markdown, diffSummary, results); err != nil {
+		t.Fatalf("WriteResultsReport: %v", err)
+	}
+	if err := WriteCSV(&global, RulePivot(incidents, diffProjects)); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	if err := WriteCSV(&globalDiffs, RulePivot(diffs, diffProjects)); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	return map[string][]byte{
+		"diffs_results.md": markdown.Bytes(),
+		"diffs_global.csv": global.Bytes(),
+		"diffs_pivot.csv":  globalDiffs.Bytes(),
+	}
+}
+
+// checkGolden compares the reports with their golden files, or rewrites them with -update.
+func checkGolden(t *testing.T, reports map[string][]byte) {
+	t.Helper()
+	for name, got := range reports {
+		golden := filepath.Join("testdata", "golden", name)
+		if *update {
+			if err := os.WriteFile(golden, got, 0644); err != nil {
+				t.Fatalf("writing %s: %v", golden, err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("reading %s: %v", golden, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s differs from %s, run go test -update to accept:\n%s", name, golden, got)
+		}
+	}
+}
+
+// checkStable compares the reports of two runs on the same input.
+func checkStable(t *testing.T, first map[string][]byte, second map[string][]byte) {
+	t.Helper()
+	for name, got := range second {
+		if !bytes.Equal(got, first[name]) {
+			t.Errorf("%s differs between two runs on the same input:\n%s\n---\n%s", name, first[name], got)
+		}
+	}
+}
+
+func TestReportsGolden(t *testing.T) {
+	checkGolden(t, goldenReports(t))
+}
+
+func TestReportsStable(t *testing.T) {
+	checkStable(t, goldenReports(t), goldenReports(t))
+}
+
+func TestDiffReportsGolden(t *testing.T) {
+	checkGolden(t, diffReports(t))
+}
+
+func TestDiffReportsStable(t *testing.T) {
+	checkStable(t, diffReports(t), diffReports(t))
+}
@@ -0,0 +1,142 @@
+package testcase
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RunStatus classifies an AppCat run from the artifacts it left in its output folder.
+type RunStatus string
+
+const (
+	// RunComplete: output.yaml, or the static report data, was written and the log reports no provider crash.
+	RunComplete RunStatus = "COMPLETE"
+	// RunPartial: AppCat ran but no incidents can be read, or a provider crashed.
+	RunPartial RunStatus = "PARTIAL"
+	// RunFailed: AppCat left no artifact at all.
+	RunFailed RunStatus = "FAILED"
+)
+
+const (
+	OutputFileName       = "output.yaml"
+	DependenciesFileName = "dependencies.yaml"
+	StaticReportFolder   = "static-report"
+	// ExpectedRunFileName in a baseline folder overrides the run status expected from the baseline artifacts.
+	ExpectedRunFileName = "expected_run.yaml"
+)
+
+// runArtifacts are the AppCat output files and folders checked by ClassifyRun.
+var runArtifacts = []string{OutputFileName, AnalysisLogFileName, DependenciesFileName, StaticReportFolder}
+
+// RunClassification is the status of an AppCat run with the reasons for it.
+type RunClassification struct {
+	Status    RunStatus       `yaml:"status"`
+	Reasons   []string        `yaml:"reasons,omitempty"`
+	Artifacts map[string]bool `yaml:"-"`
+	// LogSummary summarizes the analysis.log of the run, nil when there is none.
+	LogSummary *LogSummary `yaml:"-"`
+}
+
+// HasOutput reports whether output.yaml exists and is not empty, or the static report data
+// exists, so incidents can be parsed.
+func (c RunClassification) HasOutput() bool {
+	return c.Artifacts[OutputFileName] || c.Artifacts[staticReportData]
+}
+
+// Summary returns a single line description of the classification for reports.
+func (c RunClassification) Summary() string {
+	if len(c.Reasons) == 0 {
+		return string(c.Status)
+	}
+	return fmt.Sprintf("%s: %s", c.Status, strings.Join(c.Reasons, "; "))
+}
+
+// ClassifyRun classifies the AppCat run whose output is in outputFolder.
+func ClassifyRun(outputFolder string) RunClassification {
+	classification := RunClassification{Artifacts: make(map[string]bool)}
+	// Incidents are read from the static report data when only the static report was archived
+	classification.Artifacts[staticReportData] = len(staticReportDataFiles(outputFolder)) > 0
+	found := 0
+	for _, artifact := range runArtifacts {
+		info, err := os.Stat(filepath.Join(outputFolder, artifact))
+		if err != nil {
+			// dependencies.yaml is only written for projects with a build file
+			if artifact == OutputFileName && classification.Artifacts[staticReportData] {
+				classification.Reasons = append(classification.Reasons, fmt.Sprintf("%s is missing, incidents are read from the static report", artifact))
+			} else if artifact != DependenciesFileName {
+				classification.Reasons = append(classification.Reasons, fmt.Sprintf("%s is missing", artifact))
+			}
+			continue
+		}
+		found++
+		if artifact == OutputFileName && info.Size() == 0 {
+			classification.Reasons = append(classification.Reasons, fmt.Sprintf("%s is empty", artifact))
+			continue
+		}
+		classification.Artifacts[artifact] = true
+	}
+	if found == 0 {
+		classification.Status = RunFailed
+		classification.Reasons = []string{fmt.Sprintf("no AppCat artifact in %s", outputFolder)}
+		return classification
+	}
+
+	crashed := false
+	if classification.Artifacts[AnalysisLogFileName] {
+		summary, err := ParseAnalysisLog(filepath.Join(outputFolder, AnalysisLogFileName))
+		if err != nil {
+			classification.Reasons = append(classification.Reasons, fmt.Sprintf("%s cannot be read: %v", AnalysisLogFileName, err))
+		} else {
+			classification.LogSummary = summary
+			if count := summary.Signatures["language_server_crash"]; count > 0 {
+				crashed = true
+				classification.Reasons = append(classification.Reasons, fmt.Sprintf("%s reports %d language server crashes", AnalysisLogFileName, count))
+			}
+		}
+	}
+	if classification.HasOutput() && !crashed {
+		classification.Status = RunComplete
+	} else {
+		classification.Status = RunPartial
+	}
+	return classification
+}
+
+// ExpectedRunStatus returns the run status a baseline expects: the status set in its
+// expected_run.yaml ("status" and "reason" fields), or else the classification of the baseline run.
+func ExpectedRunStatus(baselineFolder string) (RunClassification, error) {
+	if _, err := os.Stat(baselineFolder); err != nil {
+		return RunClassification{}, fmt.Errorf("baseline folder %s does not exist", baselineFolder)
+	}
+	classification := ClassifyRun(baselineFolder)
+
+	data, err := os.ReadFile(filepath.Join(baselineFolder, ExpectedRunFileName))
+	if os.IsNotExist(err) {
+		return classification, nil
+	}
+	if err != nil {
+		return classification, fmt.Errorf("failed to read %s: %w", ExpectedRunFileName, err)
+	}
+	var expected struct {
+		Status RunStatus `yaml:"status"`
+		Reason string    `yaml:"reason"`
+	}
+	if err := yaml.Unmarshal(data, &expected); err != nil {
+		return classification, fmt.Errorf("failed to unmarshal %s: %w", ExpectedRunFileName, err)
+	}
+	switch expected.Status {
+	case RunComplete, RunPartial, RunFailed:
+	default:
+		return classification, fmt.Errorf("status '%s' in %s is not %s, %s or %s", expected.Status, ExpectedRunFileName, RunComplete, RunPartial, RunFailed)
+	}
+	classification.Status = expected.Status
+	classification.Reasons = []string{fmt.Sprintf("expected by %s", ExpectedRunFileName)}
+	if expected.Reason != "" {
+		classification.Reasons = append(classification.Reasons, expected.Reason)
+	}
+	return classification, nil
+}
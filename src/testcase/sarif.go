@@ -0,0 +1,239 @@
+package testcase
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// SarifMode controls which incidents are exported to SARIF.
+type SarifMode string
+
+const (
+	SarifNone SarifMode = "none"
+	SarifAll  SarifMode = "all"
+	SarifDiff SarifMode = "diff"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifSrcRoot = "SRCROOT"
+)
+
+// SARIF baseline states of the results exported in diff mode
+const (
+	sarifBaselineNew     = "new"
+	sarifBaselineAbsent  = "absent"
+	sarifBaselineUpdated = "updated"
+)
+
+// sarifBaselineStates maps a diff kind to the baseline state of its SARIF result.
+var sarifBaselineStates = map[DiffKind]string{
+	DiffNew:   sarifBaselineNew,
+	DiffMiss:  sarifBaselineAbsent,
+	DiffWrong: sarifBaselineUpdated,
+}
+
+type SarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []SarifRun `json:"runs"`
+}
+
+type SarifRun struct {
+	Tool    SarifTool     `json:"tool"`
+	Results []SarifResult `json:"results"`
+}
+
+type SarifTool struct {
+	Driver SarifDriver `json:"driver"`
+}
+
+type SarifDriver struct {
+	Name           string      `json:"name"`
+	InformationUri string      `json:"informationUri,omitempty"`
+	Rules          []SarifRule `json:"rules"`
+}
+
+type SarifRule struct {
+	Id               string                 `json:"id"`
+	Name             string                 `json:"name,omitempty"`
+	ShortDescription *SarifMessage          `json:"shortDescription,omitempty"`
+	HelpUri          string                 `json:"helpUri,omitempty"`
+	Help             *SarifMessage          `json:"help,omitempty"`
+	Properties       map[string]interface{} `json:"properties,omitempty"`
+}
+
+type SarifMessage struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+type SarifResult struct {
+	RuleId        string                 `json:"ruleId"`
+	RuleIndex     int                    `json:"ruleIndex"`
+	Level         string                 `json:"level"`
+	Message       SarifMessage           `json:"message"`
+	Locations     []SarifLocation        `json:"locations"`
+	BaselineState string                 `json:"baselineState,omitempty"`
+	Properties    map[string]interface{} `json:"properties,omitempty"`
+}
+
+type SarifLocation struct {
+	PhysicalLocation SarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type SarifPhysicalLocation struct {
+	ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+	Region           *SarifRegion          `json:"region,omitempty"`
+}
+
+type SarifArtifactLocation struct {
+	Uri       string `json:"uri"`
+	UriBaseId string `json:"uriBaseId,omitempty"`
+}
+
+type SarifRegion struct {
+	StartLine int           `json:"startLine"`
+	Snippet   *SarifMessage `json:"snippet,omitempty"`
+}
+
+// sarifLevel maps an AppCat rule category to a SARIF result level.
+func sarifLevel(category string) string {
+	switch category {
+	case "mandatory":
+		return "error"
+	case "potential":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifEntry is an incident to export with its baseline state, empty outside of diff mode.
+type sarifEntry struct {
+	incident      ValidateIncident
+	baselineState string
+}
+
+// BuildSarif converts parsed incidents to a SARIF log, with one driver rule per ruleset and rule.
+func (tc *TestCase) BuildSarif(incidents []ValidateIncident) *SarifLog {
+	entries := []sarifEntry{}
+	for _, incident := range incidents {
+		entries = append(entries, sarifEntry{incident: incident})
+	}
+	return tc.buildSarif(entries)
+}
+
+// BuildSarifDiffs converts validation diffs to a SARIF log, the baseline state of each result
+// telling new (NEW), absent (MISS) and updated (WRONG) incidents apart.
+func (tc *TestCase) BuildSarifDiffs(diffs []ValidationDiff) *SarifLog {
+	entries := []sarifEntry{}
+	for _, diff := range diffs {
+		entries = append(entries, sarifEntry{incident: diff.Incident, baselineState: sarifBaselineStates[diff.Kind]})
+	}
+	return tc.buildSarif(entries)
+}
+
+func (tc *TestCase) buildSarif(entries []sarifEntry) *SarifLog {
+	sorted := append([]sarifEntry{}, entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].incident, sorted[j].incident
+		if a.RuleSet != b.RuleSet {
+			return a.RuleSet < b.RuleSet
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		if a.Uri != b.Uri {
+			return a.Uri < b.Uri
+		}
+		return a.LineNumber < b.LineNumber
+	})
+
+	run := SarifRun{
+		Tool: SarifTool{Driver: SarifDriver{
+			Name:           "AppCat",
+			InformationUri: "https://learn.microsoft.com/azure/migrate/appcat/java",
+			Rules:          []SarifRule{},
+		}},
+		Results: []SarifResult{},
+	}
+	ruleIndex := make(map[string]int)
+
+	for _, entry := range sorted {
+		incident := entry.incident
+		ruleId := fmt.Sprintf("%s/%s", incident.RuleSet, incident.Rule)
+		index, exists := ruleIndex[ruleId]
+		if !exists {
+			rule := SarifRule{
+				Id:   ruleId,
+				Name: incident.Rule,
+				Properties: map[string]interface{}{
+					"ruleSet":  incident.RuleSet,
+					"category": incident.Category,
+					"effort":   incident.Effort,
+					"tags":     append([]string{}, incident.Labels...),
+				},
+			}
+			if incident.Description != "" {
+				rule.ShortDescription = &SarifMessage{Text: incident.Description}
+			}
+			if len(incident.Links) > 0 {
+				rule.HelpUri = incident.Links[0].Url
+				links := []string{}
+				for _, link := range incident.Links {
+					links = append(links, fmt.Sprintf("- [%s](%s)", link.Title, link.Url))
+				}
+				rule.Help = &SarifMessage{Text: incident.Description, Markdown: strings.Join(links, lineDelimiter)}
+			}
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[ruleId] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		message := incident.Message
+		if message == "" {
+			message = incident.Description
+		}
+		location := SarifPhysicalLocation{
+			ArtifactLocation: SarifArtifactLocation{
+				Uri:       strings.TrimPrefix(tc.relativeUri(incident.Uri), tc.Name+"/"),
+				UriBaseId: sarifSrcRoot,
+			},
+		}
+		if incident.LineNumber > 0 {
+			location.Region = &SarifRegion{StartLine: incident.LineNumber}
+			if incident.CodeSnip != "" {
+				location.Region.Snippet = &SarifMessage{Text: incident.CodeSnip}
+			}
+		}
+		run.Results = append(run.Results, SarifResult{
+			RuleId:        ruleId,
+			RuleIndex:     index,
+			Level:         sarifLevel(incident.Category),
+			Message:       SarifMessage{Text: message},
+			Locations:     []SarifLocation{{PhysicalLocation: location}},
+			BaselineState: entry.baselineState,
+		})
+	}
+
+	return &SarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []SarifRun{run}}
+}
+
+// ExportSarif writes the SARIF log to the test case output folder.
+func (tc *TestCase) ExportSarif(sarif *SarifLog) error {
+	logger := tc.getLogger("")
+	data, err := json.MarshalIndent(sarif, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal SARIF: %w", err)
+	}
+	if err := os.WriteFile(tc.getSarifFile(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write SARIF file: %w", err)
+	}
+	logger.Printf("[Sarif] %d incidents written to: %s\n", len(sarif.Runs[0].Results), tc.getSarifFile())
+	return nil
+}
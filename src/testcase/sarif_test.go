@@ -0,0 +1,43 @@
+package testcase
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBuildSarifRuleTags(t *testing.T) {
+	tc := TestCase{Name: "app"}
+	sarif := tc.BuildSarif([]ValidateIncident{
+		{RuleSet: "azure/java", Rule: "unlabeled", Uri: "file:///repos/app/App.java", Message: "No labels.", LineNumber: 1},
+		{RuleSet: "azure/java", Rule: "labeled", Uri: "file:///repos/app/App.java", Message: "Labels.", LineNumber: 2, Labels: []string{"konveyor.io/target=azure-aks"}},
+	})
+	data, err := json.Marshal(sarif)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	// SARIF requires tags to be an array
+	if strings.Contains(string(data), `"tags":null`) {
+		t.Errorf("rule tags serialized as null: %s", data)
+	}
+	if !strings.Contains(string(data), `"tags":[]`) || !strings.Contains(string(data), `"tags":["konveyor.io/target=azure-aks"]`) {
+		t.Errorf("rule tags not serialized as arrays: %s", data)
+	}
+}
+
+func TestBuildSarifDiffsBaselineState(t *testing.T) {
+	tc := TestCase{Name: "app"}
+	incident := ValidateIncident{RuleSet: "azure/java", Rule: "rule", Uri: "file:///repos/app/App.java", Message: "Message.", LineNumber: 1}
+	sarif := tc.BuildSarifDiffs([]ValidationDiff{
+		{Kind: DiffNew, Key: "a", Incident: incident},
+		{Kind: DiffWrong, Key: "b", Incident: incident},
+		{Kind: DiffMiss, Key: "c", Incident: incident},
+	})
+	states := []string{}
+	for _, result := range sarif.Runs[0].Results {
+		states = append(states, result.BaselineState)
+	}
+	if strings.Join(states, ",") != "new,updated,absent" {
+		t.Errorf("baseline states = %v, want new, updated, absent", states)
+	}
+}
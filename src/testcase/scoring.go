@@ -0,0 +1,145 @@
+package testcase
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+const (
+	// targetLabelPrefix prefixes the labels naming the migration targets of a rule.
+	targetLabelPrefix = "konveyor.io/target="
+)
+
+// Score scopes, for exports
+const (
+	ScopeTotal    = "total"
+	ScopeCategory = "category"
+	ScopeTarget   = "target"
+)
+
+// ScoreBreakdown counts incidents and their story points, the sum of the effort of each incident.
+type ScoreBreakdown struct {
+	Incidents   int `yaml:"incidents" json:"incidents"`
+	StoryPoints int `yaml:"storyPoints" json:"storyPoints"`
+}
+
+func (b *ScoreBreakdown) add(incident ValidateIncident) {
+	b.Incidents++
+	b.StoryPoints += incident.Effort
+}
+
+// MigrationScore scores the migration of a project from its incidents, in total, by rule category
+// (mandatory, optional, potential) and by target label (azure-aks, openjdk17...). An incident
+// counts for each target of its rule.
+type MigrationScore struct {
+	ScoreBreakdown `yaml:",inline"`
+	Categories     map[string]ScoreBreakdown `yaml:"categories" json:"categories"`
+	Targets        map[string]ScoreBreakdown `yaml:"targets" json:"targets"`
+}
+
+// ComputeScore scores the incidents.
+func ComputeScore(incidents map[string]ValidateIncident) *MigrationScore {
+	score := &MigrationScore{Categories: make(map[string]ScoreBreakdown), Targets: make(map[string]ScoreBreakdown)}
+	for _, incident := range incidents {
+		score.add(incident)
+		category := score.Categories[incident.Category]
+		category.add(incident)
+		score.Categories[incident.Category] = category
+		for _, label := range incident.Labels {
+			if name, isTarget := strings.CutPrefix(label, targetLabelPrefix); isTarget {
+				target := score.Targets[name]
+				target.add(incident)
+				score.Targets[name] = target
+			}
+		}
+	}
+	return score
+}
+
+// ScoreRow is a line of a score export: the total, a category or a target of a project, with the
+// baseline values when a baseline was scored.
+type ScoreRow struct {
+	Scope    string          `json:"scope"`
+	Name     string          `json:"name"`
+	Score    ScoreBreakdown  `json:"score"`
+	Baseline *ScoreBreakdown `json:"baseline,omitempty"`
+}
+
+// Changed reports whether the score differs from the baseline.
+func (r ScoreRow) Changed() bool {
+	return r.Baseline != nil && *r.Baseline != r.Score
+}
+
+// ScoreRows lists the total, categories and targets of score with their baseline values, the
+// baseline being nil when there is none. Categories and targets only in the baseline are included.
+func ScoreRows(score *MigrationScore, baseline *MigrationScore) []ScoreRow {
+	rows := []ScoreRow{{Scope: ScopeTotal, Name: ScopeTotal, Score: score.ScoreBreakdown}}
+	if baseline != nil {
+		rows[0].Baseline = &baseline.ScoreBreakdown
+	}
+	scope := func(scope string, current map[string]ScoreBreakdown, expected map[string]ScoreBreakdown) {
+		names := slices.Collect(maps.Keys(current))
+		if baseline != nil {
+			names = append(names, slices.Collect(maps.Keys(expected))...)
+		}
+		slices.Sort(names)
+		for _, name := range slices.Compact(names) {
+			row := ScoreRow{Scope: scope, Name: name, Score: current[name]}
+			if baseline != nil {
+				expectedScore := expected[name]
+				row.Baseline = &expectedScore
+			}
+			rows = append(rows, row)
+		}
+	}
+	var categories, targets map[string]ScoreBreakdown
+	if baseline != nil {
+		categories, targets = baseline.Categories, baseline.Targets
+	}
+	scope(ScopeCategory, score.Categories, categories)
+	scope(ScopeTarget, score.Targets, targets)
+	return rows
+}
+
+// ScoreDetails lists the rows of the score for reports, rows differing from the baseline marked
+// as failed.
+func ScoreDetails(rows []ScoreRow) string {
+	details := ""
+	for _, row := range rows {
+		sign := signs.PASS
+		text := fmt.Sprintf("%s %s: %d incidents, %d story points", row.Scope, row.Name, row.Score.Incidents, row.Score.StoryPoints)
+		if row.Scope == ScopeTotal {
+			text = fmt.Sprintf("%s: %d incidents, %d story points", row.Name, row.Score.Incidents, row.Score.StoryPoints)
+		}
+		if row.Baseline != nil {
+			text += fmt.Sprintf(" (baseline %d, %d)", row.Baseline.Incidents, row.Baseline.StoryPoints)
+			if row.Changed() {
+				sign = signs.FAIL
+			}
+		}
+		details += fmt.Sprintf(ItemResultFormatSUBITEM, sign, text) + lineDelimiter
+	}
+	return details
+}
+
+// RunScoring scores the incidents of the AppCat output and, when it has an output, of the baseline.
+func (tc *TestCase) RunScoring() (*MigrationScore, *MigrationScore, error) {
+	logger := tc.getLogger(ActionAnalyze)
+	parsed, err := tc.parseOutput(tc.getAppcatOutputFolder())
+	if err != nil {
+		return nil, nil, err
+	}
+	score := ComputeScore(parsed.Incidents)
+	logger.Printf("[Score] %d incidents, %d story points for project %s", score.Incidents, score.StoryPoints, tc.Name)
+
+	if len(outputFiles(tc.BaseLineFolder)) == 0 {
+		return score, nil, nil
+	}
+	baselineParsed, err := tc.parseOutput(tc.BaseLineFolder)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse baseline output: %w", err)
+	}
+	return score, ComputeScore(baselineParsed.Incidents), nil
+}
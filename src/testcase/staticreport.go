@@ -0,0 +1,186 @@
+package testcase
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// StaticReportDataFile is the static report script assigning the analysis data to window["apps"].
+	StaticReportDataFile = "output.js"
+	// StaticReportApiFolder holds JSON data files pasted into the static report instead of output.js.
+	StaticReportApiFolder = "api"
+	// staticReportData is the RunClassification artifact for static report data.
+	staticReportData = StaticReportFolder + "/data"
+)
+
+// staticReportApp is one application of the static report data. Its rulesets have the same
+// layout as output.yaml, in JSON.
+type staticReportApp struct {
+	Id       string    `json:"id"`
+	Name     string    `json:"name"`
+	RuleSets []RuleSet `json:"rulesets"`
+}
+
+// staticReportDataFiles lists the data files of the static report in outputFolder: output.js,
+// or else the JSON files of the api folder.
+func staticReportDataFiles(outputFolder string) []string {
+	dataFile := filepath.Join(outputFolder, StaticReportFolder, StaticReportDataFile)
+	if _, err := os.Stat(dataFile); err == nil {
+		return []string{dataFile}
+	}
+	files, _ := filepath.Glob(filepath.Join(outputFolder, StaticReportFolder, StaticReportApiFolder, "*.json"))
+	slices.Sort(files)
+	return files
+}
+
+// parseStaticReportData parses the apps of an output.js script (window["apps"] = [...];) or of a
+// JSON file holding an array of apps or a single app.
+func parseStaticReportData(data []byte) ([]staticReportApp, error) {
+	text := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(text, "[") && !strings.HasPrefix(text, "{") {
+		assignment := strings.Index(text, "=")
+		if assignment == -1 {
+			return nil, fmt.Errorf("no data assignment found")
+		}
+		text = strings.TrimSuffix(strings.TrimSpace(text[assignment+1:]), ";")
+	}
+	var apps []staticReportApp
+	if strings.HasPrefix(text, "{") {
+		var app staticReportApp
+		if err := json.Unmarshal([]byte(text), &app); err != nil {
+			return nil, err
+		}
+		return []staticReportApp{app}, nil
+	}
+	if err := json.Unmarshal([]byte(text), &apps); err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+// ReadStaticReport extracts the rulesets of application appName from the static report data in
+// outputFolder. When the report holds a single application it is used whatever its name.
+func ReadStaticReport(outputFolder string, appName string) ([]RuleSet, error) {
+	files := staticReportDataFiles(outputFolder)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no static report data found in folder: %s", filepath.Join(outputFolder, StaticReportFolder))
+	}
+	apps := []staticReportApp{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read static report data: %w", err)
+		}
+		fileApps, err := parseStaticReportData(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse static report data %s: %w", file, err)
+		}
+		apps = append(apps, fileApps...)
+	}
+	for _, app := range apps {
+		if app.Name == appName {
+			return app.RuleSets, nil
+		}
+	}
+	if len(apps) == 1 {
+		return apps[0].RuleSets, nil
+	}
+	return nil, fmt.Errorf("application %s not found in static report data", appName)
+}
+
+// readRuleSets calls visit for each ruleset of output.yaml in outputPath, decoded one at a time,
+// or of the static report data when only the static report was archived.
+func (tc *TestCase) readRuleSets(outputPath string, visit func(*RuleSet) error) error {
+	logger := tc.getLogger("")
+	outputFile := filepath.Join(outputPath, OutputFileName)
+	info, err := os.Stat(outputFile)
+	if (err != nil || info.Size() == 0) && len(staticReportDataFiles(outputPath)) > 0 {
+		logger.Printf("[ParseOutput] No output.yaml data in folder: %s, reading the static report", outputPath)
+		ruleSets, err := ReadStaticReport(outputPath, tc.Name)
+		if err != nil {
+			return err
+		}
+		return visitRuleSets(ruleSets)(visit)
+	}
+	if os.IsNotExist(err) {
+		logger.Errorf("No output.yaml found in folder: %s\n", outputPath)
+		return fmt.Errorf("no output.yaml found in folder: %s", outputPath)
+	}
+
+	file, err := os.Open(outputFile)
+	if err != nil {
+		logger.Errorf("failed to read output.yaml: %v", err)
+		return fmt.Errorf("failed to read output.yaml: %w", err)
+	}
+	defer file.Close()
+
+	decoder := NewOutputDecoder(file)
+	for {
+		ruleSet, err := decoder.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			logger.Errorf("failed to parse YAML: %v", err)
+			return fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		if err := visit(ruleSet); err != nil {
+			return err
+		}
+	}
+}
+
+// visitRuleSets returns the source of rulesets already in memory.
+func visitRuleSets(ruleSets []RuleSet) ruleSetSource {
+	return func(visit func(*RuleSet) error) error {
+		for index := range ruleSets {
+			if err := visit(&ruleSets[index]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// CheckStaticReport compares the incidents of output.yaml with those of the static report in
+// outputPath and lists the differences. Variables are not compared, the static report omits them.
+func (tc *TestCase) CheckStaticReport(outputPath string) ([]string, error) {
+	outputIncidents, _, _, err := tc.ParseAppCatOutput(outputPath, "")
+	if err != nil {
+		return nil, err
+	}
+	reportRuleSets, err := ReadStaticReport(outputPath, tc.Name)
+	if err != nil {
+		return nil, err
+	}
+	reportIncidents, _, _, err := tc.parseRuleSets(visitRuleSets(reportRuleSets))
+	if err != nil {
+		return nil, err
+	}
+
+	differences := []string{}
+	for _, key := range slices.Sorted(maps.Keys(outputIncidents)) {
+		reportIncident, exists := reportIncidents[key]
+		switch {
+		case !exists:
+			differences = append(differences, fmt.Sprintf("%s is missing from the static report", key))
+		case reportIncident.Message != outputIncidents[key].Message:
+			differences = append(differences, fmt.Sprintf("%s has a different message in the static report", key))
+		case reportIncident.CodeSnip != outputIncidents[key].CodeSnip:
+			differences = append(differences, fmt.Sprintf("%s has a different code snippet in the static report", key))
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(reportIncidents)) {
+		if _, exists := outputIncidents[key]; !exists {
+			differences = append(differences, fmt.Sprintf("%s is only in the static report", key))
+		}
+	}
+	return differences, nil
+}
@@ -1,20 +1,18 @@
 package testcase
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"lianwMS/appcat_validation/logger"
-	"log"
+	"log/slog"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"time"
-
-	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -46,20 +44,31 @@ var signs = Signs{
 // }
 
 const (
-	ItemResultFormatPASS    = "- [x] <b>%s</b>."
-	ItemResultFormatFAIL    = "- [ ] :x: <b>%s</b>. \n\n%s\n"
-	ItemResultFormatDETAILS = "  <details>\n  <summary> Details </summary>\n\n  %s\n\n</details>"
-	ItemResultFormatSUBITEM = "  %s %s"
+	ItemResultFormatPASS       = "- [x] <b>%s</b>."
+	ItemResultFormatFAIL       = "- [ ] :x: <b>%s</b>. \n\n%s\n"
+	ItemResultFormatDETAILS    = "  <details>\n  <summary> Details </summary>\n\n  %s\n\n</details>"
+	ItemResultFormatSUBITEM    = "  %s %s"
+	ItemResultFormatAIREVIEW   = "  <details>\n  <summary> AI Review </summary>\n\n%s\n</details>"
+	ItemResultFormatRUNSTATUS  = "  <details>\n  <summary> Run Status: %s </summary>\n\n%s\n</details>"
+	ItemResultFormatLOGHEALTH  = "  <details>\n  <summary> AppCat Log </summary>\n\n%s\n</details>"
+	ItemResultFormatHEURISTICS = "  <details>\n  <summary> Heuristic Review </summary>\n\n%s\n</details>"
+	ItemResultFormatSTATIC     = "  <details>\n  <summary> Static Report </summary>\n\n%s\n</details>"
+	ItemResultFormatSCORE      = "  <details>\n  <summary> Migration Score </summary>\n\n%s\n</details>"
 )
 
 type ActionType string
 
 const (
-	ActionRun      ActionType = "run"
-	ActionAnalyze  ActionType = "analyze"
-	ActionValidate ActionType = "validate"
+	ActionRun        ActionType = "run"
+	ActionAnalyze    ActionType = "analyze"
+	ActionValidate   ActionType = "validate"
+	ActionHeuristics ActionType = "heuristics"
+	ActionAIReview   ActionType = "ai"
 )
 
+// AllActions lists the actions in the order TestCase.Run performs them.
+var AllActions = []ActionType{ActionRun, ActionAnalyze, ActionValidate, ActionHeuristics, ActionAIReview}
+
 func containsAction(slice []ActionType, item ActionType) bool {
 	for _, v := range slice {
 		if v == item {
@@ -70,10 +79,11 @@ func containsAction(slice []ActionType, item ActionType) bool {
 }
 
 const (
-	IncidentExtension string = ".incident"
-	CSVExtension      string = ".csv"
-	YamlExtension     string = ".yaml"
-	LogExtension      string = ".log"
+	CSVExtension   string = ".csv"
+	YamlExtension  string = ".yaml"
+	LogExtension   string = ".log"
+	SarifExtension string = ".sarif"
+	GobExtension   string = ".gob"
 )
 
 type Incident struct {
@@ -84,23 +94,139 @@ type Incident struct {
 	LineNumber int         `yaml:"lineNumber"`
 }
 
+type Link struct {
+	Url   string `yaml:"url" json:"url"`
+	Title string `yaml:"title" json:"title"`
+}
+
 type Violation struct {
-	Incidents []Incident `yaml:"incidents"`
+	Description string     `yaml:"description"`
+	Category    string     `yaml:"category"`
+	Labels      []string   `yaml:"labels"`
+	Incidents   []Incident `yaml:"incidents"`
+	Links       []Link     `yaml:"links"`
+	Effort      int        `yaml:"effort"`
 }
 
 type RuleSet struct {
-	Name       string               `yaml:"name"`
-	Violations map[string]Violation `yaml:"violations"`
+	Name        string               `yaml:"name"`
+	Description string               `yaml:"description"`
+	Violations  map[string]Violation `yaml:"violations"`
+	Insights    map[string]Violation `yaml:"insights"`
+	Unmatched   []string             `yaml:"unmatched"`
+	Skipped     []string             `yaml:"skipped"`
+	Errors      map[string]string    `yaml:"errors"`
 }
 
 type ValidateIncident struct {
-	RuleSet    string      `yaml:"ruleSet"`
-	Rule       string      `yaml:"rule"`
-	Uri        string      `yaml:"uri"`
-	Message    string      `yaml:"message"`
-	CodeSnip   string      `yaml:"codeSnip"`
-	Variables  interface{} `yaml:"variables"`
-	LineNumber int         `yaml:"lineNumber"`
+	RuleSet     string      `yaml:"ruleSet" json:"ruleSet"`
+	Rule        string      `yaml:"rule" json:"rule"`
+	Description string      `yaml:"description,omitempty" json:"description,omitempty"`
+	Category    string      `yaml:"category,omitempty" json:"category,omitempty"`
+	Effort      int         `yaml:"effort,omitempty" json:"effort,omitempty"`
+	Labels      []string    `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Links       []Link      `yaml:"links,omitempty" json:"links,omitempty"`
+	Uri         string      `yaml:"uri" json:"uri"`
+	Message     string      `yaml:"message" json:"message"`
+	CodeSnip    string      `yaml:"codeSnip" json:"codeSnip"`
+	Variables   interface{} `yaml:"variables" json:"variables"`
+	LineNumber  int         `yaml:"lineNumber" json:"lineNumber"`
+}
+
+type DiffKind string
+
+const (
+	DiffNew   DiffKind = "NEW"
+	DiffWrong DiffKind = "WRONG"
+	DiffMiss  DiffKind = "MISS"
+)
+
+// diffKindOrder is the order diff kinds are reported in.
+var diffKindOrder = map[DiffKind]int{DiffNew: 0, DiffWrong: 1, DiffMiss: 2}
+
+// ValidationDiff is a single difference between the AppCat output and the baseline.
+// Incident is taken from the current output for NEW/WRONG and from the baseline for MISS.
+type ValidationDiff struct {
+	Kind     DiffKind
+	Key      string
+	Incident ValidateIncident
+	Detail   string
+}
+
+// SortDiffs returns the diffs ordered by kind and then by key, so reports are stable between runs.
+func SortDiffs(diffs map[string]ValidationDiff) []ValidationDiff {
+	sorted := slices.Collect(maps.Values(diffs))
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Kind != sorted[j].Kind {
+			return diffKindOrder[sorted[i].Kind] < diffKindOrder[sorted[j].Kind]
+		}
+		return sorted[i].Key < sorted[j].Key
+	})
+	return sorted
+}
+
+type ResultStatus string
+
+const (
+	StatusPass  ResultStatus = "PASS"
+	StatusFail  ResultStatus = "FAIL"
+	StatusError ResultStatus = "ERROR"
+)
+
+// FailureCause tells which check failed a test case.
+type FailureCause string
+
+const (
+	FailureDiffs        FailureCause = "diffs"
+	FailureRunStatus    FailureCause = "run status"
+	FailureStaticReport FailureCause = "static report"
+	FailureScore        FailureCause = "score"
+	FailureLogHealth    FailureCause = "log health"
+)
+
+// TestResult is the outcome of running all actions of a test case.
+// IncidentsCount is -1 when the AppCat output was never parsed.
+type TestResult struct {
+	Name   string
+	Status ResultStatus
+	// FailureCauses lists the checks that failed the test case, in the order they ran.
+	FailureCauses  []FailureCause
+	Message        string
+	IncidentsCount int
+	RunStatus      RunStatus
+	RuleDetails    map[string]int
+	// RuleIncidents counts the incidents by ruleset and rule, set when incidents were parsed.
+	RuleIncidents RuleCounts
+	Diffs         map[string]ValidationDiff
+	Findings      []HeuristicFinding
+	// LogSummary summarizes the AppCat analysis.log, nil when there is none.
+	LogSummary *LogSummary
+	// Coverage is the outcome of each rule in the AppCat output, set by the analyze action.
+	Coverage ProjectCoverage
+	// Score and BaselineScore score the AppCat output and the baseline, set by the analyze action.
+	// BaselineScore is nil when the baseline has no output.
+	Score         *MigrationScore
+	BaselineScore *MigrationScore
+	Verdicts      []AIVerdict
+	Duration      time.Duration
+}
+
+// markFailed marks the result as failed by cause, turning the PASS header of its message into a
+// FAIL header so the report matches the status.
+func (r *TestResult) markFailed(name string, cause FailureCause) {
+	r.Status = StatusFail
+	if !slices.Contains(r.FailureCauses, cause) {
+		r.FailureCauses = append(r.FailureCauses, cause)
+	}
+	passHeader := fmt.Sprintf(ItemResultFormatPASS, name)
+	if strings.HasPrefix(r.Message, passHeader) {
+		r.Message = strings.TrimSuffix(fmt.Sprintf(ItemResultFormatFAIL, name, ""), lineDelimiter) + strings.TrimPrefix(r.Message, passHeader)
+	}
+}
+
+// FailedOnlyBy reports whether the test case failed and cause is the only check that failed it.
+func (r TestResult) FailedOnlyBy(cause FailureCause) bool {
+	return r.Status == StatusFail && len(r.FailureCauses) == 1 && r.FailureCauses[0] == cause
 }
 
 type TestCase struct {
@@ -110,6 +236,62 @@ type TestCase struct {
 	OutputFolder      string
 	BaseLineFolder    string
 	ActionList        []ActionType
+	SarifMode         SarifMode
+	// ExistingOutputFolder, when set, is a pre-existing AppCat output folder used instead of running AppCat.
+	ExistingOutputFolder string
+	// Judge reviews incidents for the ai action, either the validation diffs or all incidents.
+	Judge         IncidentJudge
+	AIReviewScope AIReviewScope
+	AIParallelism int
+	// VerdictCache, when set, reuses verdicts from previous reviews of the same incident.
+	VerdictCache *VerdictCache
+	// Prompt is the AI review prompt, DefaultPromptTemplate when nil.
+	Prompt *PromptTemplate
+	// PromptContextLines source lines before and after each incident are added to the prompt.
+	PromptContextLines int
+	// StderrTailLines last lines of AppCat stderr are included in the error when AppCat fails.
+	StderrTailLines int
+	// OutputCache, when set, reuses outputs parsed by previous runs.
+	OutputCache *OutputCache
+	// FailOnLogRegression fails validation when analysis.log counts exceed the baseline, which
+	// otherwise only marks them in the report.
+	FailOnLogRegression bool
+
+	// parsed holds the outputs parsed while Run is running, by output path.
+	parsed map[string]ParsedOutput
+
+	// logger writes to the global log and to the test case log file while Run is running.
+	logger *logger.Logger
+}
+
+// getLogger returns the test case logger with the action field, or the global logger with the
+// project field outside of Run.
+func (tc *TestCase) getLogger(action ActionType) *logger.Logger {
+	caseLogger := tc.logger
+	if caseLogger == nil {
+		caseLogger = logger.Get().WithProject(tc.Name)
+	}
+	if action == "" {
+		return caseLogger
+	}
+	return caseLogger.WithAction(string(action))
+}
+
+// openLog creates the test case log file and the logger writing to it and to the global log.
+// The returned function closes the file.
+func (tc *TestCase) openLog() (func(), error) {
+	if err := os.MkdirAll(tc.OutputFolder, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output folder: %w", err)
+	}
+	logFile, err := os.Create(tc.getCaseLogFile())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create test case log file: %w", err)
+	}
+	tc.logger = logger.Get().WithOutput(logFile, tc.Name).WithProject(tc.Name)
+	return func() {
+		tc.logger = nil
+		logFile.Close()
+	}, nil
 }
 
 func (tc *TestCase) GetInfo() string {
@@ -118,9 +300,24 @@ func (tc *TestCase) GetInfo() string {
 }
 
 func (tc *TestCase) getAppcatOutputFolder() string {
+	if tc.ExistingOutputFolder != "" {
+		return tc.ExistingOutputFolder
+	}
 	return filepath.Join(tc.OutputFolder, "appcat_output")
 }
 
+func (tc *TestCase) getCaseLogFile() string {
+	return filepath.Join(tc.OutputFolder, fmt.Sprintf("%s%s", "test_case", LogExtension))
+}
+
+func (tc *TestCase) getAppcatStdoutFile() string {
+	return filepath.Join(tc.OutputFolder, fmt.Sprintf("%s%s", "appcat_stdout", LogExtension))
+}
+
+func (tc *TestCase) getAppcatStderrFile() string {
+	return filepath.Join(tc.OutputFolder, fmt.Sprintf("%s%s", "appcat_stderr", LogExtension))
+}
+
 func (tc *TestCase) getAnalysisOutputFolder() string {
 	return filepath.Join(tc.OutputFolder, "analysis_output")
 }
@@ -129,66 +326,328 @@ func (tc *TestCase) getIncidentsSummaryFile() string {
 	return filepath.Join(tc.getAnalysisOutputFolder(), fmt.Sprintf("%s%s", "incidents_summary", CSVExtension))
 }
 
-func (tc *TestCase) Run() (string, int, map[string]int, error) {
-	logger := logger.Get()
-	resultMessage := ""
-	icmCount := -1
-	analyzeDetails := make(map[string]int)
+func (tc *TestCase) getAIReviewFile() string {
+	return filepath.Join(tc.getAnalysisOutputFolder(), fmt.Sprintf("%s%s", "ai_review", YamlExtension))
+}
+
+func (tc *TestCase) getHeuristicsFile() string {
+	return filepath.Join(tc.getAnalysisOutputFolder(), fmt.Sprintf("%s%s", "heuristics", YamlExtension))
+}
+
+func (tc *TestCase) getAIReviewProgressFile() string {
+	return filepath.Join(tc.getAnalysisOutputFolder(), "ai_review_progress.jsonl")
+}
+
+func (tc *TestCase) getSarifFile() string {
+	return filepath.Join(tc.OutputFolder, fmt.Sprintf("%s%s", "appcat", SarifExtension))
+}
+
+// relativeUri substrings uri from the first occurrence of tc.Name and includes the tc.Name,
+// so incidents from different machines or checkouts can be compared.
+func (tc *TestCase) relativeUri(uri string) string {
+	startIndex := strings.Index(uri, tc.Name)
+	if startIndex != -1 {
+		return uri[startIndex:]
+	}
+	return uri
+}
+
+func (tc *TestCase) Run() (TestResult, error) {
+	start := time.Now()
+	result := TestResult{Name: tc.Name, Status: StatusPass, IncidentsCount: -1, RuleDetails: make(map[string]int)}
+	fail := func(err error) (TestResult, error) {
+		result.Status = StatusError
+		result.Duration = time.Since(start)
+		return result, err
+	}
+
+	closeLog, err := tc.openLog()
+	if err != nil {
+		return fail(err)
+	}
+	defer closeLog()
+	logger := tc.getLogger("")
+	tc.parsed = make(map[string]ParsedOutput)
+	defer func() { tc.parsed = nil }()
 
 	if containsAction(tc.ActionList, ActionRun) {
 		if _, err := tc.RunAppCat(); err != nil {
-			logger.Printf("[AppCat] Error running AppCat for project %s: %v", tc.Name, err)
-			return "", -1, nil, fmt.Errorf("error running AppCat for project %s: %w", tc.Name, err)
+			logger.Errorf("[AppCat] Error running AppCat for project %s: %v", tc.Name, err)
+			return fail(fmt.Errorf("error running AppCat for project %s: %w", tc.Name, err))
 		}
 	}
 
-	if containsAction(tc.ActionList, ActionAnalyze) {
+	// Steps reading incidents are skipped when the run left no output.yaml
+	classification := ClassifyRun(tc.getAppcatOutputFolder())
+	result.RunStatus = classification.Status
+	logger.Printf("[RunStatus] AppCat run of project %s: %s", tc.Name, classification.Summary())
+	hasOutput := classification.HasOutput()
+	if !hasOutput {
+		logger.Warnf("[RunStatus] No incidents to analyze for project %s, skipping steps reading %s", tc.Name, OutputFileName)
+	}
+
+	if containsAction(tc.ActionList, ActionAnalyze) && hasOutput {
 		if count, details, err := tc.RunAnalyze(); err != nil {
-			logger.Printf("[Analyze] Error analyzing output for project %s: %v", tc.Name, err)
-			return "", -1, nil, fmt.Errorf("error analyzing output for project %s: %w", tc.Name, err)
+			logger.Errorf("[Analyze] Error analyzing output for project %s: %v", tc.Name, err)
+			return fail(fmt.Errorf("error analyzing output for project %s: %w", tc.Name, err))
 		} else {
-			analyzeDetails = details
-			icmCount = count
+			result.RuleDetails = details
+			result.IncidentsCount = count
+		}
+		// Already parsed by the analysis
+		parsed, err := tc.parseOutput(tc.getAppcatOutputFolder())
+		if err != nil {
+			logger.Errorf("[Coverage] Error reading rule coverage for project %s: %v", tc.Name, err)
+			return fail(fmt.Errorf("error reading rule coverage for project %s: %w", tc.Name, err))
 		}
+		result.Coverage = parsed.Coverage
 	}
 
+	if tc.SarifMode == SarifAll && hasOutput {
+		incidents, err := tc.readIncidents()
+		if err != nil {
+			logger.Errorf("[Sarif] Error parsing output for project %s: %v", tc.Name, err)
+			return fail(fmt.Errorf("error parsing output for project %s: %w", tc.Name, err))
+		}
+		all := []ValidateIncident{}
+		for _, key := range slices.Sorted(maps.Keys(incidents)) {
+			all = append(all, incidents[key])
+		}
+		if err := tc.ExportSarif(tc.BuildSarif(all)); err != nil {
+			logger.Errorf("[Sarif] Error exporting SARIF for project %s: %v", tc.Name, err)
+			return fail(fmt.Errorf("error exporting SARIF for project %s: %w", tc.Name, err))
+		}
+	}
+
+	expected := RunClassification{Status: RunComplete}
 	if containsAction(tc.ActionList, ActionValidate) {
-		_, caseResults, err := tc.RunValidate()
+		var err error
+		if expected, err = ExpectedRunStatus(tc.BaseLineFolder); err != nil {
+			logger.Errorf("[Validate] Error reading baseline run status for project %s: %v", tc.Name, err)
+			return fail(fmt.Errorf("error reading baseline run status for project %s: %w", tc.Name, err))
+		}
+	}
+
+	if containsAction(tc.ActionList, ActionValidate) && classification.Status != expected.Status {
+		logger.Warnf("[Validate] Run status %s of project %s differs from the baseline: %s", classification.Status, tc.Name, expected.Summary())
+		result.Status = StatusFail
+		result.FailureCauses = append(result.FailureCauses, FailureRunStatus)
+		details := fmt.Sprintf(ItemResultFormatSUBITEM, signs.FAIL, "Run: "+classification.Summary()) + lineDelimiter +
+			fmt.Sprintf(ItemResultFormatSUBITEM, signs.FAIL, "Baseline: "+expected.Summary()) + lineDelimiter
+		result.Message = fmt.Sprintf(ItemResultFormatFAIL, tc.Name, fmt.Sprintf(ItemResultFormatDETAILS, details))
+	} else if containsAction(tc.ActionList, ActionValidate) && !(hasOutput && expected.HasOutput()) {
+		// A partial run expected by the baseline passes, without incidents to compare
+		logger.Printf("[Validate] Run status %s of project %s matches the baseline, incidents are not compared", classification.Status, tc.Name)
+		result.Message = fmt.Sprintf(ItemResultFormatPASS, tc.Name)
+	} else if containsAction(tc.ActionList, ActionValidate) {
+		_, caseResults, ruleDetails, err := tc.RunValidate()
 		if err != nil {
-			logger.Printf("[Validate] Error validating output for project %s: %v", tc.Name, err)
-			return "", -1, nil, fmt.Errorf("error validating output for project %s: %w", tc.Name, err)
+			logger.Errorf("[Validate] Error validating output for project %s: %v", tc.Name, err)
+			return fail(fmt.Errorf("error validating output for project %s: %w", tc.Name, err))
+		}
+		result.Diffs = caseResults
+		if result.IncidentsCount < 0 {
+			result.RuleDetails = ruleDetails
+			result.IncidentsCount = 0
+			for _, count := range ruleDetails {
+				result.IncidentsCount += count
+			}
+		}
+		if tc.SarifMode == SarifDiff {
+			if err := tc.ExportSarif(tc.BuildSarifDiffs(SortDiffs(caseResults))); err != nil {
+				logger.Errorf("[Sarif] Error exporting SARIF for project %s: %v", tc.Name, err)
+				return fail(fmt.Errorf("error exporting SARIF for project %s: %w", tc.Name, err))
+			}
 		}
 		if len(caseResults) == 0 {
-			resultMessage = fmt.Sprintf(ItemResultFormatPASS, tc.Name)
+			result.Message = fmt.Sprintf(ItemResultFormatPASS, tc.Name)
 		} else {
+			result.Status = StatusFail
+			result.FailureCauses = append(result.FailureCauses, FailureDiffs)
+			details := ""
+			for _, value := range SortDiffs(caseResults) {
+				details += value.Detail + lineDelimiter
+			}
+			result.Message = fmt.Sprintf(ItemResultFormatFAIL, tc.Name, fmt.Sprintf(ItemResultFormatDETAILS, details))
+		}
+	}
+
+	if (containsAction(tc.ActionList, ActionAnalyze) || containsAction(tc.ActionList, ActionValidate)) && hasOutput {
+		// Already parsed by the analysis or the validation
+		if parsed, err := tc.parseOutput(tc.getAppcatOutputFolder()); err == nil {
+			result.RuleIncidents = CountIncidentRules(parsed.Incidents)
+		}
+	}
+
+	if classification.Status != RunComplete {
+		details := ""
+		for _, reason := range classification.Reasons {
+			details += fmt.Sprintf(ItemResultFormatSUBITEM, signs.FAIL, reason) + lineDelimiter
+		}
+		if result.Message == "" {
+			result.Message = fmt.Sprintf(ItemResultFormatPASS, tc.Name)
+		}
+		result.Message += lineDelimiter + fmt.Sprintf(ItemResultFormatRUNSTATUS, classification.Status, details)
+	}
+
+	// The static report and output.yaml are validated against each other when the run wrote both
+	if containsAction(tc.ActionList, ActionValidate) && classification.Artifacts[OutputFileName] && classification.Artifacts[staticReportData] {
+		differences, err := tc.CheckStaticReport(tc.getAppcatOutputFolder())
+		if err != nil {
+			logger.Errorf("[StaticReport] Error checking static report for project %s: %v", tc.Name, err)
+			return fail(fmt.Errorf("error checking static report for project %s: %w", tc.Name, err))
+		}
+		logger.Printf("[StaticReport] %d differences between the static report and %s for project %s", len(differences), OutputFileName, tc.Name)
+		if result.Message == "" {
+			result.Message = fmt.Sprintf(ItemResultFormatPASS, tc.Name)
+		}
+		if len(differences) > 0 {
+			details := ""
+			for _, difference := range differences {
+				details += fmt.Sprintf(ItemResultFormatSUBITEM, signs.FAIL, difference) + lineDelimiter
+			}
+			result.Message += lineDelimiter + fmt.Sprintf(ItemResultFormatSTATIC, details)
+			result.markFailed(tc.Name, FailureStaticReport)
+		}
+	}
+
+	// A score differing from the baseline fails validation, even when incident messages match
+	if containsAction(tc.ActionList, ActionAnalyze) && hasOutput {
+		score, baseline, err := tc.RunScoring()
+		if err != nil {
+			logger.Errorf("[Score] Error scoring output for project %s: %v", tc.Name, err)
+			return fail(fmt.Errorf("error scoring output for project %s: %w", tc.Name, err))
+		}
+		result.Score, result.BaselineScore = score, baseline
+		rows := ScoreRows(score, baseline)
+		changed := false
+		for _, row := range rows {
+			if row.Changed() && containsAction(tc.ActionList, ActionValidate) {
+				logger.Warnf("[Score] Score of %s %s differs from the baseline for project %s", row.Scope, row.Name, tc.Name)
+				changed = true
+			}
+		}
+		if result.Message == "" {
+			result.Message = fmt.Sprintf(ItemResultFormatPASS, tc.Name)
+		}
+		result.Message += lineDelimiter + fmt.Sprintf(ItemResultFormatSCORE, ScoreDetails(rows))
+		if changed {
+			result.markFailed(tc.Name, FailureScore)
+		}
+	}
+
+	if (containsAction(tc.ActionList, ActionAnalyze) || containsAction(tc.ActionList, ActionValidate)) && classification.LogSummary != nil {
+		summary := classification.LogSummary
+		if err := tc.RunLogAnalysis(summary); err != nil {
+			logger.Errorf("[AnalysisLog] Error analyzing AppCat log for project %s: %v", tc.Name, err)
+			return fail(fmt.Errorf("error analyzing AppCat log for project %s: %w", tc.Name, err))
+		}
+		result.LogSummary = summary
+		// The baseline log was parsed with the baseline run status when validating
+		baseline := expected.LogSummary
+		if !containsAction(tc.ActionList, ActionValidate) {
+			if baseline, err = ReadAnalysisLog(tc.BaseLineFolder); err != nil {
+				logger.Errorf("[AnalysisLog] Error parsing baseline AppCat log for project %s: %v", tc.Name, err)
+				return fail(fmt.Errorf("error parsing baseline AppCat log for project %s: %w", tc.Name, err))
+			}
+		}
+		if result.Message == "" {
+			result.Message = fmt.Sprintf(ItemResultFormatPASS, tc.Name)
+		}
+		details, regressed := LogHealthDetails(summary, baseline)
+		result.Message += lineDelimiter + fmt.Sprintf(ItemResultFormatLOGHEALTH, details)
+		if regressed && tc.FailOnLogRegression && containsAction(tc.ActionList, ActionValidate) {
+			logger.Warnf("[AnalysisLog] AppCat log counts of project %s exceed the baseline", tc.Name)
+			result.markFailed(tc.Name, FailureLogHealth)
+		}
+	}
+
+	if containsAction(tc.ActionList, ActionHeuristics) && hasOutput {
+		findings, err := tc.RunHeuristics()
+		if err != nil {
+			logger.Errorf("[Heuristics] Error checking incidents for project %s: %v", tc.Name, err)
+			return fail(fmt.Errorf("error checking incidents for project %s: %w", tc.Name, err))
+		}
+		result.Findings = findings
+		if result.Message == "" {
+			result.Message = fmt.Sprintf(ItemResultFormatPASS, tc.Name)
+		}
+		if len(findings) > 0 {
 			details := ""
-			for _, value := range caseResults {
-				details += value + lineDelimiter
+			for _, finding := range findings {
+				details += fmt.Sprintf(ItemResultFormatSUBITEM, signs.FAIL, finding.Summary()) + lineDelimiter
 			}
-			resultMessage = fmt.Sprintf(ItemResultFormatFAIL, tc.Name, fmt.Sprintf(ItemResultFormatDETAILS, details))
+			result.Message += lineDelimiter + fmt.Sprintf(ItemResultFormatHEURISTICS, details)
 		}
 	}
 
-	return resultMessage, icmCount, analyzeDetails, nil
+	if containsAction(tc.ActionList, ActionAIReview) {
+		if tc.Judge == nil {
+			return fail(fmt.Errorf("no AI judge configured for project %s", tc.Name))
+		}
+		// Review only new and changed incidents, unless all incidents are requested
+		candidates := make(map[string]ValidateIncident)
+		if tc.AIReviewScope == AIReviewDiff {
+			if !containsAction(tc.ActionList, ActionValidate) {
+				logger.Errorf("[AIReview] AI review scope %s of project %s requires action '%s'", AIReviewDiff, tc.Name, ActionValidate)
+				return fail(fmt.Errorf("AI review scope %s of project %s requires action '%s'", AIReviewDiff, tc.Name, ActionValidate))
+			}
+			for key, diff := range result.Diffs {
+				if diff.Kind != DiffMiss {
+					candidates[key] = diff.Incident
+				}
+			}
+		} else if hasOutput {
+			incidents, err := tc.readIncidents()
+			if err != nil {
+				logger.Errorf("[AIReview] Error parsing output for project %s: %v", tc.Name, err)
+				return fail(fmt.Errorf("error parsing output for project %s: %w", tc.Name, err))
+			}
+			candidates = incidents
+		}
+		verdicts, err := tc.RunAIReview(candidates)
+		if err != nil {
+			logger.Errorf("[AIReview] Error reviewing incidents for project %s: %v", tc.Name, err)
+			return fail(fmt.Errorf("error reviewing incidents for project %s: %w", tc.Name, err))
+		}
+		result.Verdicts = verdicts
+		if result.Message == "" {
+			result.Message = fmt.Sprintf(ItemResultFormatPASS, tc.Name)
+		}
+		if len(verdicts) > 0 {
+			details := ""
+			for _, verdict := range verdicts {
+				sign := signs.PASS
+				if !verdict.IsTruePositive() {
+					sign = signs.FAIL
+				}
+				details += fmt.Sprintf(ItemResultFormatSUBITEM, sign, verdict.Summary()) + lineDelimiter
+			}
+			result.Message += lineDelimiter + fmt.Sprintf(ItemResultFormatAIREVIEW, details)
+		}
+	}
+
+	result.Duration = time.Since(start)
+	return result, nil
 }
 
 func (tc *TestCase) RunAppCat() (string, error) {
-	logger := logger.Get()
+	logger := tc.getLogger(ActionRun)
 	logger.Printf("[AppCat] Would run AppCat analysis for project: %s (%s)", tc.Name, tc.ProjectFolder)
 
 	if _, err := os.Stat(tc.ProjectFolder); os.IsNotExist(err) {
-		logger.Fatalf("[AppCat] The candidate project folder path '%s' does not exist", tc.ProjectFolder)
+		logger.Errorf("[AppCat] The candidate project folder path '%s' does not exist", tc.ProjectFolder)
 		return "", fmt.Errorf("[AppCat] The candidate project folder path '%s' does not exist", tc.ProjectFolder)
 	}
 	if _, err := os.Stat(tc.getAppcatOutputFolder()); os.IsNotExist(err) {
 		if err := os.MkdirAll(tc.getAppcatOutputFolder(), 0755); err != nil {
-			logger.Fatalf("[AppCat] Failed to create output folder: %v", err)
+			logger.Errorf("[AppCat] Failed to create output folder: %v", err)
 			return "", fmt.Errorf("[AppCat] Failed to create output folder: %w", err)
 		}
 	}
 
-	logger.Printf("[AppCat] Project: %s\n", tc.ProjectFolder)
-	logger.Printf("[AppCat] Output: %s\n", tc.getAppcatOutputFolder())
+	logger.Debugf("[AppCat] Project: %s\n", tc.ProjectFolder)
+	logger.Debugf("[AppCat] Output: %s\n", tc.getAppcatOutputFolder())
 	logger.Printf("[AppCat] Start run AppCat at %s\n", time.Now())
 
 	// Prepare command
@@ -201,13 +660,30 @@ func (tc *TestCase) RunAppCat() (string, error) {
 		"--overwrite",
 	)
 	cmd.Dir = tc.ApplicationFolder
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+
+	// Capture AppCat output, keeping the end of stderr for the failure message
+	stdoutFile, err := os.Create(tc.getAppcatStdoutFile())
+	if err != nil {
+		return "", fmt.Errorf("[AppCat] Failed to create stdout file: %w", err)
+	}
+	defer stdoutFile.Close()
+	stderrFile, err := os.Create(tc.getAppcatStderrFile())
+	if err != nil {
+		return "", fmt.Errorf("[AppCat] Failed to create stderr file: %w", err)
+	}
+	defer stderrFile.Close()
+	stderrTail := newTailWriter(tc.StderrTailLines)
+	cmd.Stdout = stdoutFile
+	cmd.Stderr = io.MultiWriter(stderrFile, stderrTail)
+	logger.Debugf("[AppCat] Output captured to: %s, %s", tc.getAppcatStdoutFile(), tc.getAppcatStderrFile())
 
 	// Run command
 	if err := cmd.Run(); err != nil {
-		logger.Fatalf("[AppCat] Error: Failed to process %s: %v", tc.ProjectFolder, err)
-		return "", fmt.Errorf("[AppCat] Error: Failed to process %s: %w", tc.ProjectFolder, err)
+		logger.Errorf("[AppCat] Error: Failed to process %s: %v", tc.ProjectFolder, err)
+		return "", &AppCatError{
+			Err:    fmt.Errorf("[AppCat] Error: Failed to process %s: %w", tc.ProjectFolder, err),
+			Stderr: stderrTail.Lines(),
+		}
 	}
 
 	logger.Printf("[AppCat] AppCat completed at %s\n", time.Now())
@@ -216,46 +692,42 @@ func (tc *TestCase) RunAppCat() (string, error) {
 }
 
 func (tc *TestCase) RunAnalyze() (int, map[string]int, error) {
-	logger := logger.Get()
+	logger := tc.getLogger(ActionAnalyze)
 	logger.Printf("[Analyze] Would run output analysis for project: %s (output: %s)", tc.Name, tc.getAnalysisOutputFolder())
-	logger.Printf("[Analyze] AppCat output: %s\n", tc.getAppcatOutputFolder())
-	logger.Printf("[Analyze] Analyze output: %s\n", tc.getAnalysisOutputFolder())
+	logger.Debugf("[Analyze] AppCat output: %s\n", tc.getAppcatOutputFolder())
+	logger.Debugf("[Analyze] Analyze output: %s\n", tc.getAnalysisOutputFolder())
 
 	// Ensure analyze output folder exists
 	if _, err := os.Stat(tc.getAnalysisOutputFolder()); os.IsNotExist(err) {
 		if err := os.MkdirAll(tc.getAnalysisOutputFolder(), 0755); err != nil {
-			logger.Fatalf("failed to create analyze output folder: %v", err)
+			logger.Errorf("failed to create analyze output folder: %v", err)
+			return 0, nil, fmt.Errorf("failed to create analyze output folder: %w", err)
 		}
 	}
 
-	outputFile := filepath.Join(tc.getAppcatOutputFolder(), "output.yaml")
-	if _, err := os.Stat(outputFile); os.IsNotExist(err) {
-		logger.Fatalf("No output.yaml found in folder: %s\n", tc.getAppcatOutputFolder())
-		return 0, nil, nil
-	}
-
 	_, rulesDetails, totalIncidents, err := tc.ParseAppCatOutput(tc.getAppcatOutputFolder(), tc.getAnalysisOutputFolder())
 	if err != nil {
-		logger.Fatalf("[Analyze] Error parsing AppCat output: %v", err)
+		logger.Errorf("[Analyze] Error parsing AppCat output: %v", err)
 		return 0, nil, fmt.Errorf("[Analyze] Error parsing AppCat output: %w", err)
 	}
 
 	logger.Printf("[Analyze] Total # of incidents found in %s: %d\n", tc.Name, totalIncidents)
-	logger.Printf("[Analyze] Rules details for %s:\n", tc.Name)
-	for rule, count := range rulesDetails {
-		logger.Printf("  %s: %d\n", rule, count)
+	logger.Debugf("[Analyze] Rules details for %s:\n", tc.Name)
+	for _, rule := range slices.Sorted(maps.Keys(rulesDetails)) {
+		logger.Debugf("  %s: %d\n", rule, rulesDetails[rule])
 	}
 
 	// write summary to analyze output folder
 
 	summaryFile, err := os.Create(tc.getIncidentsSummaryFile())
 	if err != nil {
-		logger.Fatalf("Failed to create summary file: %v", err)
+		logger.Errorf("Failed to create summary file: %v", err)
+		return 0, nil, fmt.Errorf("failed to create summary file: %w", err)
 	}
 	defer summaryFile.Close()
 	summaryFile.WriteString("Rule,Incidents\n")
-	for rule, count := range rulesDetails {
-		summaryFile.WriteString(fmt.Sprintf("%s,%d\n", rule, count))
+	for _, rule := range slices.Sorted(maps.Keys(rulesDetails)) {
+		summaryFile.WriteString(fmt.Sprintf("%s,%d\n", rule, rulesDetails[rule]))
 	}
 	logger.Printf("[Analyze] Summary written to: %s\n", tc.getIncidentsSummaryFile())
 
@@ -274,269 +746,192 @@ func (tc *TestCase) RunAnalyze() (int, map[string]int, error) {
 }
 
 func (tc *TestCase) ParseAppCatOutput(outputPath string, presistPath string) (map[string]ValidateIncident, map[string]int, int, error) {
-	logger := logger.Get()
-	logger.Printf("[ParseOutput] Parsing output from: %s\n", outputPath)
+	logger := tc.getLogger("")
+	logger.Debugf("[ParseOutput] Parsing output from: %s\n", outputPath)
 
-	outputFile := filepath.Join(outputPath, "output.yaml")
-	if _, err := os.Stat(outputFile); os.IsNotExist(err) {
-		logger.Fatalf("No output.yaml found in folder: %s\n", outputPath)
-		return nil, nil, 0, fmt.Errorf("no output.yaml found in folder: %s", outputPath)
+	parsed, err := tc.parseOutput(outputPath)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if presistPath != "" {
+		if err := tc.writeIncidentStore(presistPath, parsed.Incidents); err != nil {
+			logger.Errorf("Failed to write incident store: %v", err)
+			return nil, nil, 0, err
+		}
+		logger.Debugf("[ParseOutput] Incidents written to: %s", filepath.Join(presistPath, IncidentsFileName))
 	}
+	return parsed.Incidents, parsed.RuleDetails, parsed.Count, nil
+}
 
-	data, err := os.ReadFile(outputFile)
-	if err != nil {
-		logger.Fatalf("failed to read output.yaml: %v", err)
+// parseOutput parses the AppCat output in outputPath once per run, and once across runs with the
+// output cache.
+func (tc *TestCase) parseOutput(outputPath string) (ParsedOutput, error) {
+	logger := tc.getLogger("")
+	// Outputs do not change once AppCat ran, so they are hashed only to look up the output cache
+	path := filepath.Clean(outputPath)
+	if parsed, exists := tc.parsed[path]; exists {
+		logger.Debugf("[ParseOutput] Reusing output parsed in this run: %s", outputPath)
+		return parsed, nil
+	}
+	key := ""
+	if tc.OutputCache != nil {
+		var err error
+		if key, err = tc.outputHash(outputPath); err != nil {
+			return ParsedOutput{}, err
+		}
+		if key != "" {
+			if parsed, exists := tc.OutputCache.Get(key); exists {
+				logger.Printf("[ParseOutput] Reusing cached output: %s", outputPath)
+				if tc.parsed != nil {
+					tc.parsed[path] = parsed
+				}
+				return parsed, nil
+			}
+		}
 	}
 
-	var yamlContent []RuleSet
-	if err := yaml.Unmarshal(data, &yamlContent); err != nil {
-		logger.Fatalf("failed to parse YAML: %v", err)
+	coverage := make(ProjectCoverage)
+	incidents, ruleDetails, count, err := tc.parseRuleSets(func(visit func(*RuleSet) error) error {
+		return tc.readRuleSets(outputPath, func(ruleSet *RuleSet) error {
+			coverage.Add(ruleSet)
+			return visit(ruleSet)
+		})
+	})
+	if err != nil {
+		return ParsedOutput{}, err
+	}
+	parsed := ParsedOutput{Incidents: incidents, RuleDetails: ruleDetails, Count: count, Coverage: coverage}
+	if tc.parsed != nil {
+		tc.parsed[path] = parsed
 	}
+	if key != "" {
+		if err := tc.OutputCache.Put(key, parsed); err != nil {
+			logger.Warnf("[ParseOutput] Error caching parsed output: %v", err)
+		}
+	}
+	return parsed, nil
+}
 
+// ruleSetSource calls visit for each ruleset of an AppCat output, stopping at the first error.
+type ruleSetSource func(visit func(*RuleSet) error) error
+
+// parseRuleSets builds the incidents of the rulesets from source by key, with the incident count
+// by rule. Rulesets are processed one at a time, so only the incidents are kept in memory.
+func (tc *TestCase) parseRuleSets(source ruleSetSource) (map[string]ValidateIncident, map[string]int, int, error) {
+	logger := tc.getLogger("")
+	// Incident traces are formatted only when enabled, outputs can have 100k+ incidents
+	debug := logger.Enabled(slog.LevelDebug)
 	incidentsCount := 0
 	ruleIncidentDetails := make(map[string]int)
 	incidentsDetails := make(map[string]ValidateIncident)
 
-	for _, section := range yamlContent {
+	err := source(func(section *RuleSet) error {
 		rulesetName := section.Name
-		logger.Printf("[ParseOutput] Processing ruleset: %s\n", rulesetName)
-		if section.Violations != nil {
-			for ruleName, violation := range section.Violations {
-				logger.Printf("  [ParseOutput] Processing rule: %s\n", ruleName)
-				if len(violation.Incidents) > 0 {
-					for i, incident := range violation.Incidents {
-						incidentsCount++
-						ruleIncidentDetails[ruleName]++
-						logger.Printf("    [ParseOutput] Processing incidents: %v %v\n", incident.Uri, incident.LineNumber)
-						vIncident := ValidateIncident{
-							RuleSet:    rulesetName,
-							Rule:       ruleName,
-							Uri:        incident.Uri,
-							CodeSnip:   incident.CodeSnip,
-							Message:    incident.Message,
-							LineNumber: incident.LineNumber,
-							Variables:  incident.Variables,
-						}
-
-						// substring vincident.Uri from the first occurrence of tc.Name and include the tc.Name.
-						tempPath := vIncident.Uri
-						startIndex := strings.Index(vIncident.Uri, tc.Name)
-						if startIndex != -1 {
-							tempPath = vIncident.Uri[startIndex:]
-						}
-						key := fmt.Sprintf("%s-%s-%s-%d", vIncident.RuleSet, vIncident.Rule, tempPath, vIncident.LineNumber)
-						logger.Printf("    [ParseOutput] Incident key: %s\n", key)
-						if _, exists := incidentsDetails[key]; !exists {
-							incidentsDetails[key] = vIncident
-						} else {
-							logger.Printf("[ParseOutput] Duplicate incident found in baseline: %s", key)
-						}
-
-						if presistPath != "" {
-							incidentFileName := fmt.Sprintf("%s_%d%s", ruleName, i, IncidentExtension)
-							incidentFilePath := filepath.Join(presistPath, incidentFileName)
-
-							incidentDetails, _ := yaml.Marshal(vIncident)
-							if err := os.WriteFile(incidentFilePath, []byte(incidentDetails), 0644); err != nil {
-								logger.Fatalf("Failed to write incident file: %v", err)
-							}
-						}
-					}
+		logger.WithRule(rulesetName, "").Debugf("[ParseOutput] Processing ruleset: %s\n", rulesetName)
+		if section.Violations == nil {
+			logger.Debugf("[ParseOutput] No violations found for rule '%s'.\n", rulesetName)
+			return nil
+		}
+		for _, ruleName := range slices.Sorted(maps.Keys(section.Violations)) {
+			violation := section.Violations[ruleName]
+			logger.WithRule(rulesetName, ruleName).Debugf("  [ParseOutput] Processing rule: %s\n", ruleName)
+			if len(violation.Incidents) == 0 {
+				logger.Debugf("  [ParseOutput] No incidents found for rule: %s\n", ruleName)
+				continue
+			}
+			for _, incident := range violation.Incidents {
+				incidentsCount++
+				ruleIncidentDetails[ruleName]++
+				if debug {
+					logger.Debugf("    [ParseOutput] Processing incidents: %v %v\n", incident.Uri, incident.LineNumber)
+				}
+				vIncident := ValidateIncident{
+					RuleSet:     rulesetName,
+					Rule:        ruleName,
+					Description: violation.Description,
+					Category:    violation.Category,
+					Effort:      violation.Effort,
+					Labels:      violation.Labels,
+					Links:       violation.Links,
+					Uri:         incident.Uri,
+					CodeSnip:    incident.CodeSnip,
+					Message:     incident.Message,
+					LineNumber:  incident.LineNumber,
+					Variables:   incident.Variables,
+				}
+
+				key := fmt.Sprintf("%s-%s-%s-%d", vIncident.RuleSet, vIncident.Rule, tc.relativeUri(vIncident.Uri), vIncident.LineNumber)
+				if debug {
+					logger.Debugf("    [ParseOutput] Incident key: %s\n", key)
+				}
+				if _, exists := incidentsDetails[key]; !exists {
+					incidentsDetails[key] = vIncident
 				} else {
-					logger.Printf("  [ParseOutput] No incidents found for rule: %s\n", ruleName)
+					logger.Warnf("[ParseOutput] Duplicate incident found in baseline: %s", key)
 				}
+
 			}
-		} else {
-			logger.Printf("[ParseOutput] No violations found for rule '%s'.\n", rulesetName)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, 0, err
 	}
 	return incidentsDetails, ruleIncidentDetails, incidentsCount, nil
 }
 
-func (tc *TestCase) RunValidate() (bool, map[string]string, error) {
-	logger := logger.Get()
+func (tc *TestCase) RunValidate() (bool, map[string]ValidationDiff, map[string]int, error) {
+	logger := tc.getLogger(ActionValidate)
 	logger.Printf("[Validate] Would validate output for project: %s (output: %s)", tc.Name, tc.getAppcatOutputFolder())
-	logger.Printf("[Validate] Analyze output: %s\n", tc.getAppcatOutputFolder())
-	logger.Printf("[Validate] baseLineFolder: %s\n", tc.BaseLineFolder)
+	logger.Debugf("[Validate] Analyze output: %s\n", tc.getAppcatOutputFolder())
+	logger.Debugf("[Validate] baseLineFolder: %s\n", tc.BaseLineFolder)
 
 	baselineIncidents, _, _, err := tc.ParseAppCatOutput(tc.BaseLineFolder, "")
 	if err != nil {
-		logger.Fatalf("[Validate] Error parsing baseline output: %v", err)
-		return false, nil, fmt.Errorf("[Validate] Error parsing baseline output: %w", err)
+		logger.Errorf("[Validate] Error parsing baseline output: %v", err)
+		return false, nil, nil, fmt.Errorf("[Validate] Error parsing baseline output: %w", err)
 	}
 	logger.Printf("[Validate] Read %d baseline incidents from folder: %s\n", len(baselineIncidents), tc.BaseLineFolder)
 
-	incidents, _, _, err := tc.ParseAppCatOutput(tc.getAppcatOutputFolder(), "")
+	incidents, ruleDetails, _, err := tc.ParseAppCatOutput(tc.getAppcatOutputFolder(), "")
 	if err != nil {
-		logger.Fatalf("[Validate] Error parsing analyze output: %v", err)
-		return false, nil, fmt.Errorf("[Validate] Error parsing analyze output: %w", err)
+		logger.Errorf("[Validate] Error parsing analyze output: %v", err)
+		return false, nil, nil, fmt.Errorf("[Validate] Error parsing analyze output: %w", err)
 	}
 	logger.Printf("[Validate] Read %d incidents from analyze output folder: %s\n", len(incidents), tc.getAnalysisOutputFolder())
 
 	result := true
-	resultDetails := make(map[string]string)
+	resultDetails := make(map[string]ValidationDiff)
 	// Validate each incident against the baseline
-	for key, incident := range incidents {
+	for _, key := range slices.Sorted(maps.Keys(incidents)) {
+		incident := incidents[key]
 		baselineIncident, exists := baselineIncidents[key]
 		if !exists {
-			logger.Printf("[Validate] Incident %s not found in baseline, marking as false", key)
+			logger.Warnf("[Validate] Incident %s not found in baseline, marking as false", key)
 			result = false
-			resultDetails[key] = fmt.Sprintf("[NEW] : %s", key)
+			resultDetails[key] = ValidationDiff{Kind: DiffNew, Key: key, Incident: incident, Detail: fmt.Sprintf("[NEW] : %s", key)}
 			continue
 		}
 		if incident.Message != baselineIncident.Message {
-			logger.Printf("[Validate] Incident %s message mismatch: %s != %s", key, incident.Message, baselineIncident.Message)
+			logger.Warnf("[Validate] Incident %s message mismatch: %s != %s", key, incident.Message, baselineIncident.Message)
 			result = false
-			resultDetails[key] = fmt.Sprintf("[WRONG] :%s message mismatch: %s != %s", key, incident.Message, baselineIncident.Message)
+			resultDetails[key] = ValidationDiff{Kind: DiffWrong, Key: key, Incident: incident, Detail: fmt.Sprintf("[WRONG] :%s message mismatch: %s != %s", key, incident.Message, baselineIncident.Message)}
 			continue
 		}
 
-		logger.Printf("[Validate] Incident %s validated successfully", key)
+		logger.Debugf("[Validate] Incident %s validated successfully", key)
 	}
 
-	for key := range baselineIncidents {
+	for _, key := range slices.Sorted(maps.Keys(baselineIncidents)) {
+		baselineIncident := baselineIncidents[key]
 		if _, exists := incidents[key]; !exists {
-			logger.Printf("[Validate] Baseline incident %s not found in analyze output, marking as false", key)
+			logger.Warnf("[Validate] Baseline incident %s not found in analyze output, marking as false", key)
 			result = false
-			resultDetails[key] = fmt.Sprintf("[MISS]: %s", key)
+			resultDetails[key] = ValidationDiff{Kind: DiffMiss, Key: key, Incident: baselineIncident, Detail: fmt.Sprintf("[MISS]: %s", key)}
 			continue
 		}
 	}
 
 	logger.Printf("[Validate] Validation completed for project: %s", tc.Name)
-	return result, resultDetails, nil
-}
-
-// Following code will not be use now, but can be used later to validate the output
-func ValidateOutputAI(analyzeOutputFolder string, logger *log.Logger) error {
-	logger.Printf("[Validate] Validating output in folder: %s\n", analyzeOutputFolder)
-	azureOpenAIEndpoint := "https://openai-acl4o26y5lrhk.openai.azure.com/"
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		logger.Fatalf("ERROR: %s", err)
-	}
-	client, err := azopenai.NewClient(azureOpenAIEndpoint, cred, nil)
-	if err != nil {
-		logger.Fatalf("ERROR: %s", err)
-	}
-
-	// For range in analyzeOutputFolder, check for files with .prompt extension
-	files, err := os.ReadDir(analyzeOutputFolder)
-	if err != nil {
-		logger.Fatalf("Failed to read analyze output folder: %v", err)
-	}
-	for _, file := range files {
-		if !file.IsDir() && filepath.Ext(file.Name()) == IncidentExtension {
-			filePath := filepath.Join(analyzeOutputFolder, file.Name())
-			promptData, err := os.ReadFile(filePath)
-			if err != nil {
-				logger.Fatalf("failed to read %s: %v", filePath, err)
-			}
-			userPrompt := fmt.Sprintf("[Tool Result]\n%s[/Tool Result]", promptData)
-			logger.Printf("[Validate] Validating file: %s\n", filePath)
-			// Call ValidateSingleIncident function
-
-			result := ValidateSingleIncidentAI(userPrompt, client, logger)
-
-			// Save the result to a file
-			resultFileName := fmt.Sprintf("%s%s", file.Name(), YamlExtension)
-			resultFilePath := filepath.Join(analyzeOutputFolder, resultFileName)
-			resultData, err := yaml.Marshal(result)
-			if err != nil {
-				logger.Fatalf("Failed to marshal validation result: %v", err)
-			}
-			if err := os.WriteFile(resultFilePath, resultData, 0644); err != nil {
-				logger.Fatalf("Failed to write validation result file: %v", err)
-			}
-
-		}
-	}
-	return nil
-}
-
-func ValidateSingleIncidentAI(userPrompt_incident string, client *azopenai.Client, logger *log.Logger) map[string]interface{} {
-	result := map[string]interface{}{} // Initialize result map
-
-	// logger.Printf("[Validate] Validating incident: %s\n", userPrompt_incident)
-
-	// Call Azure OpenAI API to validate the incident, using managed identity
-	deploymentName := "llm-gpt-4o"
-
-	// Define the parameters
-	maxTokens := int32(800)
-	temperature := float32(0.7)
-	topP := float32(0.95)
-	frequencyPenalty := float32(0)
-	presencePenalty := float32(0)
-	var stop []string
-
-	messages := []azopenai.ChatRequestMessageClassification{
-		&azopenai.ChatRequestSystemMessage{Content: azopenai.NewChatRequestSystemMessageContent(SystemPrompt)},
-		&azopenai.ChatRequestUserMessage{Content: azopenai.NewChatRequestUserMessageContent(userPrompt_incident)},
-	}
-
-	resp, err := client.GetChatCompletions(context.TODO(), azopenai.ChatCompletionsOptions{
-		Messages:         messages,
-		DeploymentName:   &deploymentName,
-		MaxTokens:        &maxTokens,
-		Temperature:      &temperature,
-		TopP:             &topP,
-		FrequencyPenalty: &frequencyPenalty,
-		PresencePenalty:  &presencePenalty,
-		Stop:             stop,
-	}, nil)
-	if err != nil {
-		log.Fatalf("ERROR: %s", err)
-	}
-
-	// Print the response
-	for _, choice := range resp.Choices {
-		if choice.Message != nil && choice.Message.Content != nil {
-			content := *choice.Message.Content
-			start := strings.Index(content, "{")
-			end := strings.LastIndex(content, "}")
-			if start == -1 || end == -1 || start >= end {
-				logger.Fatalf("Invalid response format: %s", content)
-			}
-			jsonContent := content[start : end+1] // Extract the JSON part
-			logger.Printf("Extracted JSON content: %s\n", jsonContent)
-
-			// get "Result" and "Reason" from the json response and update the result map
-			if err := json.Unmarshal([]byte(jsonContent), &result); err != nil {
-				logger.Fatalf("Failed to unmarshal response: %v", err)
-			}
-			logger.Printf("Validation result: %v\n", result)
-		}
-	}
-	return result
-}
-
-// Const system prompt for AI validation
-const SystemPrompt = `You are an expert software engineer. 
-You will receive a [Tool Result] produced by a Azure Migrate application and code assessment for Java. This tool is designed to help organizations modernize their Java applications to reduce costs and accelerate innovation. It uses advanced static analysis techniques to understand application structure and dependencies, and provides guidance for refactoring and migrating applications to Azure.
-Each result is presented in YAML format and contains the following fields:
-  - uri: File path that contains the matching code.
-  - message: A description of the identified issue or migration recommendation.
-  - codesnip: A code snippet from the source that matches the rule.
-  - lineNumber: The specific line number in the file where the code appears.
-  - variables: The relevant variables or symbols identified in the code snippet.
-Verify whether the message, uri, codesnip, lineNumber, and variables are consistent and logically aligned.
-Common false positive is:
-  - Code match is in a README or documentation file.
-  - Code match is a comment, not actual executable code.
-  - Code match is clearly unrelated to the message or incorrect.
-  - Any part of the result does not make logical sense or seems incorrectly matched.
-
-Only need to return result with Json. [Result Sample]
-
-[Result Sample]
-If all fields are aligned and support the same finding, respond with:
-{
-  "Result": "true"
-}
-If they are not aligned, respond with:
-{
-  "Result": "false",
-  "Reason": "<brief explanation of the misalignment>"
+	return result, resultDetails, ruleDetails, nil
 }
-[/Result Sample]`
@@ -0,0 +1,100 @@
+package testcase
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type Verdict string
+
+const (
+	VerdictTruePositive  Verdict = "true_positive"
+	VerdictFalsePositive Verdict = "false_positive"
+	VerdictUncertain     Verdict = "uncertain"
+)
+
+// FalsePositiveCategory tells why an incident is a false positive, following the common
+// false positives listed in the system prompt, see prompts/default.yaml.
+type FalsePositiveCategory string
+
+const (
+	CategoryNone          FalsePositiveCategory = "none"
+	CategoryDocumentation FalsePositiveCategory = "documentation"
+	CategoryComment       FalsePositiveCategory = "comment"
+	CategoryUnrelated     FalsePositiveCategory = "unrelated"
+	CategoryInconsistent  FalsePositiveCategory = "inconsistent"
+	CategoryOther         FalsePositiveCategory = "other"
+)
+
+var (
+	verdicts   = []Verdict{VerdictTruePositive, VerdictFalsePositive, VerdictUncertain}
+	categories = []FalsePositiveCategory{CategoryNone, CategoryDocumentation, CategoryComment, CategoryUnrelated, CategoryInconsistent, CategoryOther}
+)
+
+// JudgeVerdict is the structured answer expected from the judge, see VerdictSchema.
+type JudgeVerdict struct {
+	Verdict    Verdict               `json:"verdict"`
+	Reason     string                `json:"reason"`
+	Category   FalsePositiveCategory `json:"category"`
+	Confidence float64               `json:"confidence"`
+}
+
+// VerdictSchema is the JSON schema of JudgeVerdict sent to judges supporting structured output.
+const VerdictSchema = `{
+  "type": "object",
+  "properties": {
+    "verdict": {"type": "string", "enum": ["true_positive", "false_positive", "uncertain"]},
+    "reason": {"type": "string"},
+    "category": {"type": "string", "enum": ["none", "documentation", "comment", "unrelated", "inconsistent", "other"]},
+    "confidence": {"type": "number"}
+  },
+  "required": ["verdict", "reason", "category", "confidence"],
+  "additionalProperties": false
+}`
+
+// verdictRequiredFields are the fields VerdictSchema requires, a missing field would otherwise
+// decode as its zero value.
+var verdictRequiredFields = []string{"verdict", "reason", "category", "confidence"}
+
+// VerdictSchemaName is the name of VerdictSchema in structured output requests.
+const VerdictSchemaName = "incident_verdict"
+
+// ParseJudgeVerdict strictly parses a judge answer: it must be a single JSON object matching
+// VerdictSchema, with no surrounding text, no unknown fields and every required field set.
+func ParseJudgeVerdict(content string) (JudgeVerdict, error) {
+	var verdict JudgeVerdict
+	var fields map[string]json.RawMessage
+	decoder := json.NewDecoder(bytes.NewReader([]byte(strings.TrimSpace(content))))
+	if err := decoder.Decode(&fields); err != nil {
+		return verdict, fmt.Errorf("answer is not a valid verdict JSON object: %w", err)
+	}
+	if decoder.More() {
+		return verdict, fmt.Errorf("answer contains more than one JSON value")
+	}
+	for _, field := range verdictRequiredFields {
+		if value, exists := fields[field]; !exists || string(value) == "null" {
+			return verdict, fmt.Errorf("required field '%s' is missing", field)
+		}
+	}
+	decoder = json.NewDecoder(bytes.NewReader([]byte(strings.TrimSpace(content))))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&verdict); err != nil {
+		return verdict, fmt.Errorf("answer is not a valid verdict JSON object: %w", err)
+	}
+	if !slices.Contains(verdicts, verdict.Verdict) {
+		return verdict, fmt.Errorf("verdict '%s' is not one of %v", verdict.Verdict, verdicts)
+	}
+	if !slices.Contains(categories, verdict.Category) {
+		return verdict, fmt.Errorf("category '%s' is not one of %v", verdict.Category, categories)
+	}
+	if verdict.Confidence < 0 || verdict.Confidence > 1 {
+		return verdict, fmt.Errorf("confidence %v is not between 0 and 1", verdict.Confidence)
+	}
+	if verdict.Verdict == VerdictFalsePositive && strings.TrimSpace(verdict.Reason) == "" {
+		return verdict, fmt.Errorf("a reason is required for a false_positive verdict")
+	}
+	return verdict, nil
+}
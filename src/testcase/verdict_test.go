@@ -0,0 +1,122 @@
+package testcase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const validVerdict = `{"verdict": "false_positive", "reason": "Match in a comment.", "category": "comment", "confidence": 0.9}`
+
+func TestParseJudgeVerdict(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    JudgeVerdict
+		err     string
+	}{
+		{name: "valid", content: validVerdict,
+			want: JudgeVerdict{Verdict: VerdictFalsePositive, Reason: "Match in a comment.", Category: CategoryComment, Confidence: 0.9}},
+		{name: "surrounding whitespace", content: "\n  " + validVerdict + "\n",
+			want: JudgeVerdict{Verdict: VerdictFalsePositive, Reason: "Match in a comment.", Category: CategoryComment, Confidence: 0.9}},
+		{name: "true positive without reason", content: `{"verdict": "true_positive", "reason": "", "category": "none", "confidence": 1}`,
+			want: JudgeVerdict{Verdict: VerdictTruePositive, Category: CategoryNone, Confidence: 1}},
+		{name: "leading prose", content: "Here is my verdict: " + validVerdict, err: "not a valid verdict JSON object"},
+		{name: "trailing prose", content: validVerdict + " I hope this helps.", err: "more than one JSON value"},
+		{name: "json fence", content: "```json\n" + validVerdict + "\n```", err: "not a valid verdict JSON object"},
+		{name: "trailing value", content: validVerdict + ` {"verdict": "true_positive"}`, err: "more than one JSON value"},
+		{name: "not an object", content: `["true_positive"]`, err: "not a valid verdict JSON object"},
+		{name: "missing verdict", content: `{"reason": "r", "category": "none", "confidence": 1}`, err: "'verdict' is missing"},
+		{name: "missing reason", content: `{"verdict": "true_positive", "category": "none", "confidence": 1}`, err: "'reason' is missing"},
+		{name: "missing category", content: `{"verdict": "true_positive", "reason": "", "confidence": 1}`, err: "'category' is missing"},
+		{name: "missing confidence", content: `{"verdict": "true_positive", "reason": "", "category": "none"}`, err: "'confidence' is missing"},
+		{name: "null confidence", content: `{"verdict": "true_positive", "reason": "", "category": "none", "confidence": null}`, err: "'confidence' is missing"},
+		{name: "unknown field", content: `{"verdict": "true_positive", "reason": "", "category": "none", "confidence": 1, "score": 3}`, err: "unknown field"},
+		{name: "wrong type", content: `{"verdict": "true_positive", "reason": "", "category": "none", "confidence": "high"}`, err: "not a valid verdict JSON object"},
+		{name: "bad verdict", content: `{"verdict": "maybe", "reason": "", "category": "none", "confidence": 1}`, err: "verdict 'maybe' is not one of"},
+		{name: "bad category", content: `{"verdict": "false_positive", "reason": "r", "category": "typo", "confidence": 1}`, err: "category 'typo' is not one of"},
+		{name: "confidence above 1", content: `{"verdict": "true_positive", "reason": "", "category": "none", "confidence": 1.5}`, err: "confidence 1.5 is not between 0 and 1"},
+		{name: "negative confidence", content: `{"verdict": "true_positive", "reason": "", "category": "none", "confidence": -0.1}`, err: "confidence -0.1 is not between 0 and 1"},
+		{name: "false positive without reason", content: `{"verdict": "false_positive", "reason": " ", "category": "comment", "confidence": 1}`, err: "reason is required"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseJudgeVerdict(test.content)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("ParseJudgeVerdict error = %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseJudgeVerdict: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("ParseJudgeVerdict = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+// scriptedJudge returns its answers in order and records the conversations it was sent.
+type scriptedJudge struct {
+	answers []string
+	calls   [][]JudgeMessage
+}
+
+func (j *scriptedJudge) Name() string {
+	return "scripted"
+}
+
+func (j *scriptedJudge) Complete(ctx context.Context, messages []JudgeMessage, responseSchema string) (string, error) {
+	j.calls = append(j.calls, append([]JudgeMessage{}, messages...))
+	if len(j.calls) > len(j.answers) {
+		return "", fmt.Errorf("unexpected call %d", len(j.calls))
+	}
+	return j.answers[len(j.calls)-1], nil
+}
+
+func TestValidateSingleIncidentAIRepair(t *testing.T) {
+	tests := []struct {
+		name    string
+		answers []string
+		calls   int
+		err     string
+	}{
+		{name: "valid answer", answers: []string{validVerdict}, calls: 1},
+		{name: "repaired answer", answers: []string{"```json\n" + validVerdict + "\n```", validVerdict}, calls: 2},
+		{name: "invalid after repair", answers: []string{"not json", `{"verdict": "maybe"}`}, calls: 2, err: "invalid verdict after 1 repair attempts"},
+	}
+	logger := (&TestCase{Name: "verdict"}).getLogger(ActionAIReview)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			judge := &scriptedJudge{answers: test.answers}
+			verdict, err := ValidateSingleIncidentAI("system", "incident", judge, logger)
+			if len(judge.calls) != test.calls {
+				t.Fatalf("judge called %d times, want %d", len(judge.calls), test.calls)
+			}
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("ValidateSingleIncidentAI error = %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateSingleIncidentAI: %v", err)
+			}
+			if verdict.Verdict != VerdictFalsePositive || verdict.Category != CategoryComment {
+				t.Errorf("ValidateSingleIncidentAI = %+v", verdict)
+			}
+			if test.calls < 2 {
+				return
+			}
+			// The repair request replays the invalid answer with the parse error
+			repair := judge.calls[1]
+			if len(repair) != 4 || repair[2].Role != RoleAssistant || repair[2].Content != test.answers[0] ||
+				repair[3].Role != RoleUser || !strings.Contains(repair[3].Content, "could not be parsed") {
+				t.Errorf("repair conversation = %+v", repair)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"lianwMS/appcat_validation/history"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	trendFilePrefix string = "appcat_trend"
+)
+
+// runTrend implements the "trend" command: it reads the run history in the output folder
+// and writes a Markdown trend report of the last N runs next to it.
+func runTrend(args []string) {
+	wd, _ := os.Getwd()
+	flags := flag.NewFlagSet("trend", flag.ExitOnError)
+	outputFolder := flags.String("output", filepath.Join(wd, "..", "testResults"), "Path to output folder containing the run history")
+	lastRuns := flags.Int("runs", 10, "Number of most recent runs to include")
+	threshold := flags.Float64("jump", 0.5, "Relative incident count change between runs flagged as a sudden jump (0.5 = 50%)")
+	flags.Parse(args)
+
+	records, err := history.Load(history.GetHistoryFile(*outputFolder))
+	if err != nil {
+		fmt.Printf("Error loading run history: %v\n", err)
+		os.Exit(1)
+	}
+
+	report := history.BuildTrendReport(records, *lastRuns, *threshold)
+	reportFilePath := filepath.Join(*outputFolder, fmt.Sprintf("%s_%s%s", trendFilePrefix, time.Now().Format("20060102_150405"), TestResultExtension))
+	if err := os.WriteFile(reportFilePath, []byte(report), 0644); err != nil {
+		fmt.Printf("Failed to write trend report: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(report)
+	fmt.Printf("\nTrend report written to: %s\n", reportFilePath)
+}